@@ -0,0 +1,97 @@
+package grpcserver
+
+import (
+	"context"
+	"log"
+
+	pb "loki/api/pb"
+	"loki/domain/orderbook"
+	"loki/service"
+)
+
+// AdminServer exposes instrument management over gRPC.
+type AdminServer struct {
+	pb.UnimplementedAdminServiceServer
+	engine *service.Engine
+}
+
+func NewAdminServer(engine *service.Engine) *AdminServer {
+	return &AdminServer{engine: engine}
+}
+
+func (s *AdminServer) ListInstruments(
+	ctx context.Context,
+	req *pb.ListInstrumentsRequest,
+) (*pb.ListInstrumentsResponse, error) {
+	insts := s.engine.Instruments()
+
+	resp := &pb.ListInstrumentsResponse{
+		Instruments: make([]*pb.Instrument, 0, len(insts)),
+	}
+	for _, inst := range insts {
+		resp.Instruments = append(resp.Instruments, &pb.Instrument{
+			Symbol:   inst.Symbol,
+			TickSize: inst.TickSize,
+			PostOnly: fromPostOnly(inst.PostOnly),
+
+			LotSize:      inst.Rules.LotSize,
+			MinQty:       inst.Rules.MinQty,
+			MaxQty:       inst.Rules.MaxQty,
+			MinNotional:  inst.Rules.MinNotional,
+			PriceBandBps: inst.Rules.PriceBandBps,
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *AdminServer) CreateInstrument(
+	ctx context.Context,
+	req *pb.CreateInstrumentRequest,
+) (*pb.CreateInstrumentResponse, error) {
+	in := req.GetInstrument()
+	inst := orderbook.Instrument{
+		Symbol:   in.GetSymbol(),
+		TickSize: in.GetTickSize(),
+		PostOnly: toPostOnly(in.GetPostOnly()),
+		Rules: orderbook.Rules{
+			LotSize:      in.GetLotSize(),
+			MinQty:       in.GetMinQty(),
+			MaxQty:       in.GetMaxQty(),
+			MinNotional:  in.GetMinNotional(),
+			PriceBandBps: in.GetPriceBandBps(),
+		},
+	}
+
+	seq, err := s.engine.CreateInstrument(inst)
+
+	log.Printf(
+		"[gRPC] CreateInstrument symbol=%s tick=%d seq=%d err=%v",
+		inst.Symbol, inst.TickSize, seq, err,
+	)
+
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.CreateInstrumentResponse{
+		Status: "ok",
+		SeqId:  seq,
+	}, nil
+}
+
+// -------------------- Converters --------------------
+
+func toPostOnly(p pb.PostOnlyPolicy) orderbook.PostOnlyPolicy {
+	if p == pb.PostOnlyPolicy_POST_ONLY_SLIDE {
+		return orderbook.PostOnlySlide
+	}
+	return orderbook.PostOnlyReject
+}
+
+func fromPostOnly(p orderbook.PostOnlyPolicy) pb.PostOnlyPolicy {
+	if p == orderbook.PostOnlySlide {
+		return pb.PostOnlyPolicy_POST_ONLY_SLIDE
+	}
+	return pb.PostOnlyPolicy_POST_ONLY_REJECT
+}
@@ -0,0 +1,103 @@
+package grpcserver
+
+import (
+	"errors"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"loki/domain/orderbook"
+	"loki/service"
+)
+
+// errorDomain is the ErrorInfo domain of every status we return.
+const errorDomain = "loki"
+
+// retryAfter is the backoff hinted to clients on a full queue.
+const retryAfter = 10 * time.Millisecond
+
+// isOrderRejection reports whether err is a book rejection of a
+// sequenced order. Those are answered with a REJECTED report,
+// not a gRPC error: the order got a seq and an outbox event.
+func isOrderRejection(err error) bool {
+	return errors.Is(err, orderbook.ErrFOKNotFillable) ||
+		errors.Is(err, orderbook.ErrPostOnlyWouldCross)
+}
+
+// toStatus maps a service error onto a gRPC status carrying an
+// ErrorInfo whose reason is service.RejectReason.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	code := codes.Internal
+	switch {
+	case errors.Is(err, service.ErrValidation):
+		code = codes.InvalidArgument
+	case errors.Is(err, service.ErrUnknownOrder):
+		code = codes.NotFound
+	case errors.Is(err, service.ErrDuplicate):
+		code = codes.AlreadyExists
+	case errors.Is(err, service.ErrQueueFull):
+		code = codes.ResourceExhausted
+	case errors.Is(err, service.ErrHalted),
+		errors.Is(err, service.ErrWALUnavailable):
+		code = codes.Unavailable
+	case errors.Is(err, orderbook.ErrOrderFilled),
+		isOrderRejection(err):
+		code = codes.FailedPrecondition
+	}
+
+	st := status.New(code, err.Error())
+
+	info := &errdetails.ErrorInfo{
+		Reason: service.RejectReason(err),
+		Domain: errorDomain,
+	}
+	var withDetails *status.Status
+	switch field := badField(err); {
+	case code == codes.ResourceExhausted:
+		withDetails, err = st.WithDetails(info, &errdetails.RetryInfo{
+			RetryDelay: durationpb.New(retryAfter),
+		})
+	case field != "":
+		withDetails, err = st.WithDetails(info, &errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+				Field:       field,
+				Description: err.Error(),
+			}},
+		})
+	default:
+		withDetails, err = st.WithDetails(info)
+	}
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+// badField names the request field an order rule violation is
+// about, or "" if err is not one.
+func badField(err error) string {
+	switch {
+	case errors.Is(err, orderbook.ErrInvalidSide):
+		return "side"
+	case errors.Is(err, orderbook.ErrInvalidType):
+		return "type"
+	case errors.Is(err, orderbook.ErrInvalidPrice),
+		errors.Is(err, orderbook.ErrTickSize),
+		errors.Is(err, orderbook.ErrPriceBand):
+		return "price"
+	case errors.Is(err, orderbook.ErrInvalidQty),
+		errors.Is(err, orderbook.ErrLotSize),
+		errors.Is(err, orderbook.ErrQtyOutOfRange),
+		errors.Is(err, orderbook.ErrMinNotional):
+		return "qty"
+	default:
+		return ""
+	}
+}
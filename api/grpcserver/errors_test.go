@@ -0,0 +1,65 @@
+package grpcserver
+
+import (
+	"fmt"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "loki/api/pb"
+	"loki/domain/orderbook"
+	"loki/service"
+)
+
+func TestToStatus(t *testing.T) {
+	cases := []struct {
+		err    error
+		code   codes.Code
+		reason string
+	}{
+		{&service.Error{Kind: service.ErrUnknownOrder, Err: orderbook.ErrOrderNotFound}, codes.NotFound, "UNKNOWN_ORDER"},
+		{&service.Error{Kind: service.ErrValidation, Err: orderbook.ErrUnknownSymbol}, codes.InvalidArgument, "UNKNOWN_SYMBOL"},
+		{&service.Error{Kind: service.ErrDuplicate, Err: orderbook.ErrInstrumentExists}, codes.AlreadyExists, "DUPLICATE"},
+		{&service.Error{Kind: service.ErrHalted, Err: fmt.Errorf("disk full")}, codes.Unavailable, "HALTED"},
+		{service.ErrQueueFull, codes.ResourceExhausted, "QUEUE_FULL"},
+		{orderbook.ErrOrderFilled, codes.FailedPrecondition, "ALREADY_FILLED"},
+		{&service.Error{Kind: service.ErrValidation, Err: orderbook.ErrLotSize}, codes.InvalidArgument, "LOT_SIZE"},
+	}
+
+	for _, c := range cases {
+		st, _ := status.FromError(toStatus(c.err))
+		if st.Code() != c.code {
+			t.Errorf("%v: code %v, want %v", c.err, st.Code(), c.code)
+			continue
+		}
+
+		var reason string
+		for _, d := range st.Details() {
+			if info, ok := d.(*errdetails.ErrorInfo); ok {
+				reason = info.Reason
+			}
+		}
+		if reason != c.reason {
+			t.Errorf("%v: reason %q, want %q", c.err, reason, c.reason)
+		}
+	}
+}
+
+func TestUnsetEnumsRejected(t *testing.T) {
+	if _, err := toSide(pb.Side_SIDE_UNSPECIFIED); status.Code(toStatus(err)) != codes.InvalidArgument {
+		t.Fatalf("unset side: %v", err)
+	}
+	if _, err := toType(pb.OrderType(42)); status.Code(toStatus(err)) != codes.InvalidArgument {
+		t.Fatalf("unknown type: %v", err)
+	}
+
+	st, _ := status.FromError(toStatus(&service.Error{Kind: service.ErrValidation, Err: orderbook.ErrTickSize}))
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok && br.FieldViolations[0].Field == "price" {
+			return
+		}
+	}
+	t.Fatal("tick size violation carries no price field violation")
+}
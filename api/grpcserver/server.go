@@ -4,19 +4,22 @@ import (
 	"context"
 	"log"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
 	pb "loki/api/pb"
 	"loki/domain/orderbook"
 	"loki/service"
 )
 
-// Server adapts OrderService to gRPC.
+// Server adapts the sharded Engine to gRPC.
 type Server struct {
 	pb.UnimplementedOrderServiceServer
-	svc *service.OrderService
+	engine *service.Engine
 }
 
-func NewServer(svc *service.OrderService) *Server {
-	return &Server{svc: svc}
+func NewServer(engine *service.Engine) *Server {
+	return &Server{engine: engine}
 }
 
 // -------------------- Commands --------------------
@@ -25,10 +28,19 @@ func (s *Server) PlaceOrder(
 	ctx context.Context,
 	req *pb.PlaceOrderRequest,
 ) (*pb.PlaceOrderResponse, error) {
-	side := toSide(req.Side)
-	otype := toType(req.Type)
+	side, err := toSide(req.Side)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	otype, err := toType(req.Type)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	symbol := symbolOrDefault(req.Symbol)
 
-	seq := s.svc.PlaceOrder(
+	report, err := s.engine.PlaceOrder(
+		symbol,
 		side,
 		otype,
 		req.Price,
@@ -37,52 +49,115 @@ func (s *Server) PlaceOrder(
 	)
 
 	log.Printf(
-		"[gRPC] PlaceOrder side=%v type=%v price=%d qty=%d seq=%d",
-		side, otype, req.Price, req.Qty, seq,
+		"[gRPC] PlaceOrder symbol=%s side=%v type=%v price=%d qty=%d seq=%d status=%v err=%v",
+		symbol, side, otype, req.Price, req.Qty, report.Seq, report.Status, err,
 	)
 
-	return &pb.PlaceOrderResponse{
-		Status: "ok",
-		SeqId:  seq,
-	}, nil
+	if err != nil && !isOrderRejection(err) {
+		return nil, toStatus(err)
+	}
+
+	resp := toPlaceOrderResponse(&report)
+	if err != nil {
+		resp.Status = "REJECTED"
+		resp.Reason = service.RejectReason(err)
+	}
+
+	return resp, nil
 }
 
 func (s *Server) CancelOrder(
 	ctx context.Context,
 	req *pb.CancelOrderRequest,
 ) (*pb.CancelOrderResponse, error) {
-	// Cancellation is not implemented yet in domain.
-	// Keep API stable; implement later.
+	symbol := symbolOrDefault(req.Symbol)
+
+	seq, err := s.engine.CancelOrder(symbol, req.OrderId)
+
 	log.Printf(
-		"[gRPC] CancelOrder id=%d price=%d",
-		req.OrderId, req.Price,
+		"[gRPC] CancelOrder symbol=%s id=%d seq=%d err=%v",
+		symbol, req.OrderId, seq, err,
 	)
 
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
 	return &pb.CancelOrderResponse{
 		Status: "ok",
+		SeqId:  seq,
 	}, nil
 }
 
+func (s *Server) AmendOrder(
+	ctx context.Context,
+	req *pb.AmendOrderRequest,
+) (*pb.PlaceOrderResponse, error) {
+	symbol := symbolOrDefault(req.Symbol)
+
+	report, err := s.engine.AmendOrder(
+		symbol,
+		req.OrderId,
+		req.Price,
+		req.Qty,
+	)
+
+	log.Printf(
+		"[gRPC] AmendOrder symbol=%s id=%d price=%d qty=%d seq=%d status=%v err=%v",
+		symbol, req.OrderId, req.Price, req.Qty, report.Seq, report.Status, err,
+	)
+
+	if err != nil && !isOrderRejection(err) {
+		return nil, toStatus(err)
+	}
+
+	resp := toPlaceOrderResponse(&report)
+	if err != nil {
+		resp.Status = "REJECTED"
+		resp.Reason = service.RejectReason(err)
+	}
+
+	return resp, nil
+}
+
 // -------------------- Queries --------------------
 
+func (s *Server) GetOrder(
+	ctx context.Context,
+	req *pb.GetOrderRequest,
+) (*pb.GetOrderResponse, error) {
+	symbol := symbolOrDefault(req.Symbol)
+
+	o, ok, err := s.engine.GetOrder(symbol, req.OrderId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "order %d not resting in %s", req.OrderId, symbol)
+	}
+
+	return &pb.GetOrderResponse{
+		Status: "ok",
+		Order:  toOrderEntry(symbol, &o),
+	}, nil
+}
+
 func (s *Server) GetSnapshot(
 	ctx context.Context,
 	req *pb.SnapshotRequest,
 ) (*pb.SnapshotResponse, error) {
-	orders := s.svc.Snapshot()
+	symbol := symbolOrDefault(req.Symbol)
+	orders, err := s.engine.Snapshot(symbol)
+	if err != nil {
+		return nil, toStatus(err)
+	}
 
 	resp := &pb.SnapshotResponse{
 		Orders: make([]*pb.OrderEntry, 0, len(orders)),
 	}
 
-	for _, o := range orders {
-		resp.Orders = append(resp.Orders, &pb.OrderEntry{
-			Id:    o.ID,
-			Side:  fromSide(o.Side),
-			Type:  fromType(o.Type),
-			Price: o.Price,
-			Qty:   o.Qty,
-		})
+	for i := range orders {
+		resp.Orders = append(resp.Orders, toOrderEntry(symbol, &orders[i]))
 	}
 
 	return resp, nil
@@ -90,31 +165,96 @@ func (s *Server) GetSnapshot(
 
 // -------------------- Converters --------------------
 
-func toSide(s pb.Side) orderbook.Side {
+// symbolOrDefault keeps clients that predate the symbol field
+// trading the default instrument.
+func symbolOrDefault(symbol string) string {
+	if symbol == "" {
+		return orderbook.DefaultSymbol
+	}
+	return symbol
+}
+
+// toSide and toType refuse unset or unknown enums instead of
+// guessing, before anything is sequenced.
+func toSide(s pb.Side) (orderbook.Side, error) {
 	switch s {
 	case pb.Side_BID:
-		return orderbook.Bid
+		return orderbook.Bid, nil
 	case pb.Side_ASK:
-		return orderbook.Ask
+		return orderbook.Ask, nil
 	default:
-		return orderbook.Bid
+		return 0, &service.Error{Kind: service.ErrValidation, Err: orderbook.ErrInvalidSide}
 	}
 }
 
-func toType(t pb.OrderType) orderbook.OrderType {
+func toType(t pb.OrderType) (orderbook.OrderType, error) {
 	switch t {
 	case pb.OrderType_LIMIT:
-		return orderbook.Limit
+		return orderbook.Limit, nil
 	case pb.OrderType_MARKET:
-		return orderbook.Market
+		return orderbook.Market, nil
 	case pb.OrderType_IOC:
-		return orderbook.IOC
+		return orderbook.IOC, nil
 	case pb.OrderType_FOK:
-		return orderbook.FOK
+		return orderbook.FOK, nil
 	case pb.OrderType_POST_ONLY:
-		return orderbook.PostOnly
+		return orderbook.PostOnly, nil
 	default:
-		return orderbook.Limit
+		return 0, &service.Error{Kind: service.ErrValidation, Err: orderbook.ErrInvalidType}
+	}
+}
+
+func toPlaceOrderResponse(r *service.ExecutionReport) *pb.PlaceOrderResponse {
+	resp := &pb.PlaceOrderResponse{
+		Status:       "ok",
+		SeqId:        r.Seq,
+		Price:        r.Price,
+		Repriced:     r.Repriced,
+		OrderId:      r.OrderID,
+		ExecStatus:   fromExecStatus(r.Status),
+		FilledQty:    r.FilledQty,
+		RemainingQty: r.RemainingQty,
+		AvgPrice:     r.AvgPrice,
+		Fills:        make([]*pb.Fill, 0, len(r.Fills)),
+	}
+
+	for _, f := range r.Fills {
+		resp.Fills = append(resp.Fills, &pb.Fill{
+			MakerId: f.MakerID,
+			Price:   f.Price,
+			Qty:     f.Qty,
+		})
+	}
+
+	return resp
+}
+
+func fromExecStatus(s service.ExecStatus) pb.ExecStatus {
+	switch s {
+	case service.ExecResting:
+		return pb.ExecStatus_RESTING
+	case service.ExecPartiallyFilled:
+		return pb.ExecStatus_PARTIALLY_FILLED
+	case service.ExecFilled:
+		return pb.ExecStatus_FILLED
+	case service.ExecCanceled:
+		return pb.ExecStatus_CANCELED
+	case service.ExecRejected:
+		return pb.ExecStatus_REJECTED
+	default:
+		return pb.ExecStatus_EXEC_STATUS_UNSPECIFIED
+	}
+}
+
+func toOrderEntry(symbol string, o *orderbook.Order) *pb.OrderEntry {
+	return &pb.OrderEntry{
+		Symbol: symbol,
+		Id:     o.ID,
+		Side:   fromSide(o.Side),
+		Type:   fromType(o.Type),
+		Price:  o.Price,
+		Qty:    o.Qty,
+		Filled: o.Filled,
 	}
 }
 
@@ -128,13 +128,119 @@ func (OrderType) EnumDescriptor() ([]byte, []int) {
 	return file_api_pb_order_proto_rawDescGZIP(), []int{1}
 }
 
+type PostOnlyPolicy int32
+
+const (
+	PostOnlyPolicy_POST_ONLY_REJECT PostOnlyPolicy = 0
+	PostOnlyPolicy_POST_ONLY_SLIDE  PostOnlyPolicy = 1
+)
+
+// Enum value maps for PostOnlyPolicy.
+var (
+	PostOnlyPolicy_name = map[int32]string{
+		0: "POST_ONLY_REJECT",
+		1: "POST_ONLY_SLIDE",
+	}
+	PostOnlyPolicy_value = map[string]int32{
+		"POST_ONLY_REJECT": 0,
+		"POST_ONLY_SLIDE":  1,
+	}
+)
+
+func (x PostOnlyPolicy) Enum() *PostOnlyPolicy {
+	p := new(PostOnlyPolicy)
+	*p = x
+	return p
+}
+
+func (x PostOnlyPolicy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PostOnlyPolicy) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_pb_order_proto_enumTypes[2].Descriptor()
+}
+
+func (PostOnlyPolicy) Type() protoreflect.EnumType {
+	return &file_api_pb_order_proto_enumTypes[2]
+}
+
+func (x PostOnlyPolicy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PostOnlyPolicy.Descriptor instead.
+func (PostOnlyPolicy) EnumDescriptor() ([]byte, []int) {
+	return file_api_pb_order_proto_rawDescGZIP(), []int{2}
+}
+
+type ExecStatus int32
+
+const (
+	ExecStatus_EXEC_STATUS_UNSPECIFIED ExecStatus = 0
+	ExecStatus_RESTING                 ExecStatus = 1
+	ExecStatus_PARTIALLY_FILLED        ExecStatus = 2
+	ExecStatus_FILLED                  ExecStatus = 3
+	ExecStatus_CANCELED                ExecStatus = 4
+	ExecStatus_REJECTED                ExecStatus = 5
+)
+
+// Enum value maps for ExecStatus.
+var (
+	ExecStatus_name = map[int32]string{
+		0: "EXEC_STATUS_UNSPECIFIED",
+		1: "RESTING",
+		2: "PARTIALLY_FILLED",
+		3: "FILLED",
+		4: "CANCELED",
+		5: "REJECTED",
+	}
+	ExecStatus_value = map[string]int32{
+		"EXEC_STATUS_UNSPECIFIED": 0,
+		"RESTING":                 1,
+		"PARTIALLY_FILLED":        2,
+		"FILLED":                  3,
+		"CANCELED":                4,
+		"REJECTED":                5,
+	}
+)
+
+func (x ExecStatus) Enum() *ExecStatus {
+	p := new(ExecStatus)
+	*p = x
+	return p
+}
+
+func (x ExecStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ExecStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_pb_order_proto_enumTypes[3].Descriptor()
+}
+
+func (ExecStatus) Type() protoreflect.EnumType {
+	return &file_api_pb_order_proto_enumTypes[3]
+}
+
+func (x ExecStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ExecStatus.Descriptor instead.
+func (ExecStatus) EnumDescriptor() ([]byte, []int) {
+	return file_api_pb_order_proto_rawDescGZIP(), []int{3}
+}
+
 type PlaceOrderRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Side          Side                   `protobuf:"varint,1,opt,name=side,proto3,enum=loki.pb.Side" json:"side,omitempty"`
-	Type          OrderType              `protobuf:"varint,2,opt,name=type,proto3,enum=loki.pb.OrderType" json:"type,omitempty"`
-	Price         int64                  `protobuf:"varint,3,opt,name=price,proto3" json:"price,omitempty"`
-	Qty           int64                  `protobuf:"varint,4,opt,name=qty,proto3" json:"qty,omitempty"`
-	UserId        uint64                 `protobuf:"varint,5,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Side   Side                   `protobuf:"varint,1,opt,name=side,proto3,enum=loki.pb.Side" json:"side,omitempty"`
+	Type   OrderType              `protobuf:"varint,2,opt,name=type,proto3,enum=loki.pb.OrderType" json:"type,omitempty"`
+	Price  int64                  `protobuf:"varint,3,opt,name=price,proto3" json:"price,omitempty"`
+	Qty    int64                  `protobuf:"varint,4,opt,name=qty,proto3" json:"qty,omitempty"`
+	UserId uint64                 `protobuf:"varint,5,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// symbol defaults to DEFAULT when empty
+	Symbol        string `protobuf:"bytes,6,opt,name=symbol,proto3" json:"symbol,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -204,10 +310,29 @@ func (x *PlaceOrderRequest) GetUserId() uint64 {
 	return 0
 }
 
+func (x *PlaceOrderRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+// status is one of: ok, REJECTED
 type PlaceOrderResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	SeqId         uint64                 `protobuf:"varint,2,opt,name=seq_id,json=seqId,proto3" json:"seq_id,omitempty"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Status string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	SeqId  uint64                 `protobuf:"varint,2,opt,name=seq_id,json=seqId,proto3" json:"seq_id,omitempty"`
+	// reason is set when status is REJECTED, e.g. FOK_NOT_FILLABLE
+	Reason string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	// price is the accepted price; differs from the request when repriced
+	Price         int64      `protobuf:"varint,4,opt,name=price,proto3" json:"price,omitempty"`
+	Repriced      bool       `protobuf:"varint,5,opt,name=repriced,proto3" json:"repriced,omitempty"`
+	OrderId       uint64     `protobuf:"varint,6,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	ExecStatus    ExecStatus `protobuf:"varint,7,opt,name=exec_status,json=execStatus,proto3,enum=loki.pb.ExecStatus" json:"exec_status,omitempty"`
+	FilledQty     int64      `protobuf:"varint,8,opt,name=filled_qty,json=filledQty,proto3" json:"filled_qty,omitempty"`
+	RemainingQty  int64      `protobuf:"varint,9,opt,name=remaining_qty,json=remainingQty,proto3" json:"remaining_qty,omitempty"`
+	AvgPrice      float64    `protobuf:"fixed64,10,opt,name=avg_price,json=avgPrice,proto3" json:"avg_price,omitempty"`
+	Fills         []*Fill    `protobuf:"bytes,11,rep,name=fills,proto3" json:"fills,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -256,29 +381,92 @@ func (x *PlaceOrderResponse) GetSeqId() uint64 {
 	return 0
 }
 
-type CancelOrderRequest struct {
+func (x *PlaceOrderResponse) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *PlaceOrderResponse) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *PlaceOrderResponse) GetRepriced() bool {
+	if x != nil {
+		return x.Repriced
+	}
+	return false
+}
+
+func (x *PlaceOrderResponse) GetOrderId() uint64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *PlaceOrderResponse) GetExecStatus() ExecStatus {
+	if x != nil {
+		return x.ExecStatus
+	}
+	return ExecStatus_EXEC_STATUS_UNSPECIFIED
+}
+
+func (x *PlaceOrderResponse) GetFilledQty() int64 {
+	if x != nil {
+		return x.FilledQty
+	}
+	return 0
+}
+
+func (x *PlaceOrderResponse) GetRemainingQty() int64 {
+	if x != nil {
+		return x.RemainingQty
+	}
+	return 0
+}
+
+func (x *PlaceOrderResponse) GetAvgPrice() float64 {
+	if x != nil {
+		return x.AvgPrice
+	}
+	return 0
+}
+
+func (x *PlaceOrderResponse) GetFills() []*Fill {
+	if x != nil {
+		return x.Fills
+	}
+	return nil
+}
+
+type Fill struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	OrderId       uint64                 `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
-	Side          Side                   `protobuf:"varint,2,opt,name=side,proto3,enum=loki.pb.Side" json:"side,omitempty"`
-	Price         int64                  `protobuf:"varint,3,opt,name=price,proto3" json:"price,omitempty"`
+	MakerId       uint64                 `protobuf:"varint,1,opt,name=maker_id,json=makerId,proto3" json:"maker_id,omitempty"`
+	Price         int64                  `protobuf:"varint,2,opt,name=price,proto3" json:"price,omitempty"`
+	Qty           int64                  `protobuf:"varint,3,opt,name=qty,proto3" json:"qty,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CancelOrderRequest) Reset() {
-	*x = CancelOrderRequest{}
+func (x *Fill) Reset() {
+	*x = Fill{}
 	mi := &file_api_pb_order_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CancelOrderRequest) String() string {
+func (x *Fill) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CancelOrderRequest) ProtoMessage() {}
+func (*Fill) ProtoMessage() {}
 
-func (x *CancelOrderRequest) ProtoReflect() protoreflect.Message {
+func (x *Fill) ProtoReflect() protoreflect.Message {
 	mi := &file_api_pb_order_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -290,53 +478,60 @@ func (x *CancelOrderRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CancelOrderRequest.ProtoReflect.Descriptor instead.
-func (*CancelOrderRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use Fill.ProtoReflect.Descriptor instead.
+func (*Fill) Descriptor() ([]byte, []int) {
 	return file_api_pb_order_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *CancelOrderRequest) GetOrderId() uint64 {
+func (x *Fill) GetMakerId() uint64 {
 	if x != nil {
-		return x.OrderId
+		return x.MakerId
 	}
 	return 0
 }
 
-func (x *CancelOrderRequest) GetSide() Side {
+func (x *Fill) GetPrice() int64 {
 	if x != nil {
-		return x.Side
+		return x.Price
 	}
-	return Side_SIDE_UNSPECIFIED
+	return 0
 }
 
-func (x *CancelOrderRequest) GetPrice() int64 {
+func (x *Fill) GetQty() int64 {
 	if x != nil {
-		return x.Price
+		return x.Qty
 	}
 	return 0
 }
 
-type CancelOrderResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+type CancelOrderRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	OrderId uint64                 `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	// side and price are no longer needed to locate the order.
+	//
+	// Deprecated: Marked as deprecated in api/pb/order.proto.
+	Side Side `protobuf:"varint,2,opt,name=side,proto3,enum=loki.pb.Side" json:"side,omitempty"`
+	// Deprecated: Marked as deprecated in api/pb/order.proto.
+	Price         int64  `protobuf:"varint,3,opt,name=price,proto3" json:"price,omitempty"`
+	Symbol        string `protobuf:"bytes,4,opt,name=symbol,proto3" json:"symbol,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CancelOrderResponse) Reset() {
-	*x = CancelOrderResponse{}
+func (x *CancelOrderRequest) Reset() {
+	*x = CancelOrderRequest{}
 	mi := &file_api_pb_order_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CancelOrderResponse) String() string {
+func (x *CancelOrderRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CancelOrderResponse) ProtoMessage() {}
+func (*CancelOrderRequest) ProtoMessage() {}
 
-func (x *CancelOrderResponse) ProtoReflect() protoreflect.Message {
+func (x *CancelOrderRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_pb_order_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -348,38 +543,66 @@ func (x *CancelOrderResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CancelOrderResponse.ProtoReflect.Descriptor instead.
-func (*CancelOrderResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use CancelOrderRequest.ProtoReflect.Descriptor instead.
+func (*CancelOrderRequest) Descriptor() ([]byte, []int) {
 	return file_api_pb_order_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *CancelOrderResponse) GetStatus() string {
+func (x *CancelOrderRequest) GetOrderId() uint64 {
 	if x != nil {
-		return x.Status
+		return x.OrderId
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in api/pb/order.proto.
+func (x *CancelOrderRequest) GetSide() Side {
+	if x != nil {
+		return x.Side
+	}
+	return Side_SIDE_UNSPECIFIED
+}
+
+// Deprecated: Marked as deprecated in api/pb/order.proto.
+func (x *CancelOrderRequest) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *CancelOrderRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
 	}
 	return ""
 }
 
-type SnapshotRequest struct {
+// status is always ok. An unknown or already filled order fails
+// the call with NOT_FOUND or FAILED_PRECONDITION, with the reason
+// in ErrorInfo.
+type CancelOrderResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	SeqId         uint64                 `protobuf:"varint,2,opt,name=seq_id,json=seqId,proto3" json:"seq_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SnapshotRequest) Reset() {
-	*x = SnapshotRequest{}
+func (x *CancelOrderResponse) Reset() {
+	*x = CancelOrderResponse{}
 	mi := &file_api_pb_order_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SnapshotRequest) String() string {
+func (x *CancelOrderResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SnapshotRequest) ProtoMessage() {}
+func (*CancelOrderResponse) ProtoMessage() {}
 
-func (x *SnapshotRequest) ProtoReflect() protoreflect.Message {
+func (x *CancelOrderResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_api_pb_order_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -391,36 +614,50 @@ func (x *SnapshotRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SnapshotRequest.ProtoReflect.Descriptor instead.
-func (*SnapshotRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use CancelOrderResponse.ProtoReflect.Descriptor instead.
+func (*CancelOrderResponse) Descriptor() ([]byte, []int) {
 	return file_api_pb_order_proto_rawDescGZIP(), []int{4}
 }
 
-type OrderEntry struct {
+func (x *CancelOrderResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *CancelOrderResponse) GetSeqId() uint64 {
+	if x != nil {
+		return x.SeqId
+	}
+	return 0
+}
+
+// qty is the new total quantity, including what already filled.
+type AmendOrderRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Side          Side                   `protobuf:"varint,2,opt,name=side,proto3,enum=loki.pb.Side" json:"side,omitempty"`
-	Type          OrderType              `protobuf:"varint,3,opt,name=type,proto3,enum=loki.pb.OrderType" json:"type,omitempty"`
-	Price         int64                  `protobuf:"varint,4,opt,name=price,proto3" json:"price,omitempty"`
-	Qty           int64                  `protobuf:"varint,5,opt,name=qty,proto3" json:"qty,omitempty"`
+	OrderId       uint64                 `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Symbol        string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Price         int64                  `protobuf:"varint,3,opt,name=price,proto3" json:"price,omitempty"`
+	Qty           int64                  `protobuf:"varint,4,opt,name=qty,proto3" json:"qty,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *OrderEntry) Reset() {
-	*x = OrderEntry{}
+func (x *AmendOrderRequest) Reset() {
+	*x = AmendOrderRequest{}
 	mi := &file_api_pb_order_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *OrderEntry) String() string {
+func (x *AmendOrderRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*OrderEntry) ProtoMessage() {}
+func (*AmendOrderRequest) ProtoMessage() {}
 
-func (x *OrderEntry) ProtoReflect() protoreflect.Message {
+func (x *AmendOrderRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_pb_order_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -432,67 +669,61 @@ func (x *OrderEntry) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use OrderEntry.ProtoReflect.Descriptor instead.
-func (*OrderEntry) Descriptor() ([]byte, []int) {
+// Deprecated: Use AmendOrderRequest.ProtoReflect.Descriptor instead.
+func (*AmendOrderRequest) Descriptor() ([]byte, []int) {
 	return file_api_pb_order_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *OrderEntry) GetId() uint64 {
+func (x *AmendOrderRequest) GetOrderId() uint64 {
 	if x != nil {
-		return x.Id
+		return x.OrderId
 	}
 	return 0
 }
 
-func (x *OrderEntry) GetSide() Side {
-	if x != nil {
-		return x.Side
-	}
-	return Side_SIDE_UNSPECIFIED
-}
-
-func (x *OrderEntry) GetType() OrderType {
+func (x *AmendOrderRequest) GetSymbol() string {
 	if x != nil {
-		return x.Type
+		return x.Symbol
 	}
-	return OrderType_ORDER_TYPE_UNSPECIFIED
+	return ""
 }
 
-func (x *OrderEntry) GetPrice() int64 {
+func (x *AmendOrderRequest) GetPrice() int64 {
 	if x != nil {
 		return x.Price
 	}
 	return 0
 }
 
-func (x *OrderEntry) GetQty() int64 {
+func (x *AmendOrderRequest) GetQty() int64 {
 	if x != nil {
 		return x.Qty
 	}
 	return 0
 }
 
-type SnapshotResponse struct {
+type GetOrderRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Orders        []*OrderEntry          `protobuf:"bytes,1,rep,name=orders,proto3" json:"orders,omitempty"`
+	OrderId       uint64                 `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Symbol        string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SnapshotResponse) Reset() {
-	*x = SnapshotResponse{}
+func (x *GetOrderRequest) Reset() {
+	*x = GetOrderRequest{}
 	mi := &file_api_pb_order_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SnapshotResponse) String() string {
+func (x *GetOrderRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SnapshotResponse) ProtoMessage() {}
+func (*GetOrderRequest) ProtoMessage() {}
 
-func (x *SnapshotResponse) ProtoReflect() protoreflect.Message {
+func (x *GetOrderRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_pb_order_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -504,48 +735,622 @@ func (x *SnapshotResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SnapshotResponse.ProtoReflect.Descriptor instead.
-func (*SnapshotResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetOrderRequest.ProtoReflect.Descriptor instead.
+func (*GetOrderRequest) Descriptor() ([]byte, []int) {
 	return file_api_pb_order_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *SnapshotResponse) GetOrders() []*OrderEntry {
+func (x *GetOrderRequest) GetOrderId() uint64 {
 	if x != nil {
-		return x.Orders
+		return x.OrderId
 	}
-	return nil
+	return 0
 }
 
-var File_api_pb_order_proto protoreflect.FileDescriptor
+func (x *GetOrderRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
 
-const file_api_pb_order_proto_rawDesc = "" +
-	"\n" +
-	"\x12api/pb/order.proto\x12\aloki.pb\"\x9f\x01\n" +
-	"\x11PlaceOrderRequest\x12!\n" +
+// status is always ok. An order that is not resting fails the
+// call with NOT_FOUND.
+type GetOrderResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Order         *OrderEntry            `protobuf:"bytes,2,opt,name=order,proto3" json:"order,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderResponse) Reset() {
+	*x = GetOrderResponse{}
+	mi := &file_api_pb_order_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderResponse) ProtoMessage() {}
+
+func (x *GetOrderResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pb_order_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderResponse.ProtoReflect.Descriptor instead.
+func (*GetOrderResponse) Descriptor() ([]byte, []int) {
+	return file_api_pb_order_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetOrderResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *GetOrderResponse) GetOrder() *OrderEntry {
+	if x != nil {
+		return x.Order
+	}
+	return nil
+}
+
+type SnapshotRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotRequest) Reset() {
+	*x = SnapshotRequest{}
+	mi := &file_api_pb_order_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotRequest) ProtoMessage() {}
+
+func (x *SnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pb_order_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotRequest.ProtoReflect.Descriptor instead.
+func (*SnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_api_pb_order_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SnapshotRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+type OrderEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Side          Side                   `protobuf:"varint,2,opt,name=side,proto3,enum=loki.pb.Side" json:"side,omitempty"`
+	Type          OrderType              `protobuf:"varint,3,opt,name=type,proto3,enum=loki.pb.OrderType" json:"type,omitempty"`
+	Price         int64                  `protobuf:"varint,4,opt,name=price,proto3" json:"price,omitempty"`
+	Qty           int64                  `protobuf:"varint,5,opt,name=qty,proto3" json:"qty,omitempty"`
+	Filled        int64                  `protobuf:"varint,6,opt,name=filled,proto3" json:"filled,omitempty"`
+	Symbol        string                 `protobuf:"bytes,7,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderEntry) Reset() {
+	*x = OrderEntry{}
+	mi := &file_api_pb_order_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderEntry) ProtoMessage() {}
+
+func (x *OrderEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pb_order_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderEntry.ProtoReflect.Descriptor instead.
+func (*OrderEntry) Descriptor() ([]byte, []int) {
+	return file_api_pb_order_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *OrderEntry) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *OrderEntry) GetSide() Side {
+	if x != nil {
+		return x.Side
+	}
+	return Side_SIDE_UNSPECIFIED
+}
+
+func (x *OrderEntry) GetType() OrderType {
+	if x != nil {
+		return x.Type
+	}
+	return OrderType_ORDER_TYPE_UNSPECIFIED
+}
+
+func (x *OrderEntry) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *OrderEntry) GetQty() int64 {
+	if x != nil {
+		return x.Qty
+	}
+	return 0
+}
+
+func (x *OrderEntry) GetFilled() int64 {
+	if x != nil {
+		return x.Filled
+	}
+	return 0
+}
+
+func (x *OrderEntry) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+type SnapshotResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Orders        []*OrderEntry          `protobuf:"bytes,1,rep,name=orders,proto3" json:"orders,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotResponse) Reset() {
+	*x = SnapshotResponse{}
+	mi := &file_api_pb_order_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotResponse) ProtoMessage() {}
+
+func (x *SnapshotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pb_order_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotResponse.ProtoReflect.Descriptor instead.
+func (*SnapshotResponse) Descriptor() ([]byte, []int) {
+	return file_api_pb_order_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SnapshotResponse) GetOrders() []*OrderEntry {
+	if x != nil {
+		return x.Orders
+	}
+	return nil
+}
+
+type Instrument struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Symbol   string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	TickSize int64                  `protobuf:"varint,2,opt,name=tick_size,json=tickSize,proto3" json:"tick_size,omitempty"`
+	PostOnly PostOnlyPolicy         `protobuf:"varint,3,opt,name=post_only,json=postOnly,proto3,enum=loki.pb.PostOnlyPolicy" json:"post_only,omitempty"`
+	// Order rules; 0 means no limit.
+	LotSize       int64 `protobuf:"varint,4,opt,name=lot_size,json=lotSize,proto3" json:"lot_size,omitempty"`
+	MinQty        int64 `protobuf:"varint,5,opt,name=min_qty,json=minQty,proto3" json:"min_qty,omitempty"`
+	MaxQty        int64 `protobuf:"varint,6,opt,name=max_qty,json=maxQty,proto3" json:"max_qty,omitempty"`
+	MinNotional   int64 `protobuf:"varint,7,opt,name=min_notional,json=minNotional,proto3" json:"min_notional,omitempty"`
+	PriceBandBps  int64 `protobuf:"varint,8,opt,name=price_band_bps,json=priceBandBps,proto3" json:"price_band_bps,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Instrument) Reset() {
+	*x = Instrument{}
+	mi := &file_api_pb_order_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Instrument) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Instrument) ProtoMessage() {}
+
+func (x *Instrument) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pb_order_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Instrument.ProtoReflect.Descriptor instead.
+func (*Instrument) Descriptor() ([]byte, []int) {
+	return file_api_pb_order_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Instrument) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Instrument) GetTickSize() int64 {
+	if x != nil {
+		return x.TickSize
+	}
+	return 0
+}
+
+func (x *Instrument) GetPostOnly() PostOnlyPolicy {
+	if x != nil {
+		return x.PostOnly
+	}
+	return PostOnlyPolicy_POST_ONLY_REJECT
+}
+
+func (x *Instrument) GetLotSize() int64 {
+	if x != nil {
+		return x.LotSize
+	}
+	return 0
+}
+
+func (x *Instrument) GetMinQty() int64 {
+	if x != nil {
+		return x.MinQty
+	}
+	return 0
+}
+
+func (x *Instrument) GetMaxQty() int64 {
+	if x != nil {
+		return x.MaxQty
+	}
+	return 0
+}
+
+func (x *Instrument) GetMinNotional() int64 {
+	if x != nil {
+		return x.MinNotional
+	}
+	return 0
+}
+
+func (x *Instrument) GetPriceBandBps() int64 {
+	if x != nil {
+		return x.PriceBandBps
+	}
+	return 0
+}
+
+type ListInstrumentsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListInstrumentsRequest) Reset() {
+	*x = ListInstrumentsRequest{}
+	mi := &file_api_pb_order_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListInstrumentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListInstrumentsRequest) ProtoMessage() {}
+
+func (x *ListInstrumentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pb_order_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListInstrumentsRequest.ProtoReflect.Descriptor instead.
+func (*ListInstrumentsRequest) Descriptor() ([]byte, []int) {
+	return file_api_pb_order_proto_rawDescGZIP(), []int{12}
+}
+
+type ListInstrumentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Instruments   []*Instrument          `protobuf:"bytes,1,rep,name=instruments,proto3" json:"instruments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListInstrumentsResponse) Reset() {
+	*x = ListInstrumentsResponse{}
+	mi := &file_api_pb_order_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListInstrumentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListInstrumentsResponse) ProtoMessage() {}
+
+func (x *ListInstrumentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pb_order_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListInstrumentsResponse.ProtoReflect.Descriptor instead.
+func (*ListInstrumentsResponse) Descriptor() ([]byte, []int) {
+	return file_api_pb_order_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListInstrumentsResponse) GetInstruments() []*Instrument {
+	if x != nil {
+		return x.Instruments
+	}
+	return nil
+}
+
+type CreateInstrumentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Instrument    *Instrument            `protobuf:"bytes,1,opt,name=instrument,proto3" json:"instrument,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateInstrumentRequest) Reset() {
+	*x = CreateInstrumentRequest{}
+	mi := &file_api_pb_order_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateInstrumentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateInstrumentRequest) ProtoMessage() {}
+
+func (x *CreateInstrumentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pb_order_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateInstrumentRequest.ProtoReflect.Descriptor instead.
+func (*CreateInstrumentRequest) Descriptor() ([]byte, []int) {
+	return file_api_pb_order_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CreateInstrumentRequest) GetInstrument() *Instrument {
+	if x != nil {
+		return x.Instrument
+	}
+	return nil
+}
+
+// status is always ok. A duplicate or invalid instrument fails the
+// call with ALREADY_EXISTS or INVALID_ARGUMENT, with the reason in
+// ErrorInfo.
+type CreateInstrumentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	SeqId         uint64                 `protobuf:"varint,2,opt,name=seq_id,json=seqId,proto3" json:"seq_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateInstrumentResponse) Reset() {
+	*x = CreateInstrumentResponse{}
+	mi := &file_api_pb_order_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateInstrumentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateInstrumentResponse) ProtoMessage() {}
+
+func (x *CreateInstrumentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pb_order_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateInstrumentResponse.ProtoReflect.Descriptor instead.
+func (*CreateInstrumentResponse) Descriptor() ([]byte, []int) {
+	return file_api_pb_order_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *CreateInstrumentResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *CreateInstrumentResponse) GetSeqId() uint64 {
+	if x != nil {
+		return x.SeqId
+	}
+	return 0
+}
+
+var File_api_pb_order_proto protoreflect.FileDescriptor
+
+const file_api_pb_order_proto_rawDesc = "" +
+	"\n" +
+	"\x12api/pb/order.proto\x12\aloki.pb\"\xb7\x01\n" +
+	"\x11PlaceOrderRequest\x12!\n" +
 	"\x04side\x18\x01 \x01(\x0e2\r.loki.pb.SideR\x04side\x12&\n" +
 	"\x04type\x18\x02 \x01(\x0e2\x12.loki.pb.OrderTypeR\x04type\x12\x14\n" +
 	"\x05price\x18\x03 \x01(\x03R\x05price\x12\x10\n" +
 	"\x03qty\x18\x04 \x01(\x03R\x03qty\x12\x17\n" +
-	"\auser_id\x18\x05 \x01(\x04R\x06userId\"C\n" +
+	"\auser_id\x18\x05 \x01(\x04R\x06userId\x12\x16\n" +
+	"\x06symbol\x18\x06 \x01(\tR\x06symbol\"\xe4\x02\n" +
 	"\x12PlaceOrderResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12\x15\n" +
-	"\x06seq_id\x18\x02 \x01(\x04R\x05seqId\"h\n" +
+	"\x06seq_id\x18\x02 \x01(\x04R\x05seqId\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x14\n" +
+	"\x05price\x18\x04 \x01(\x03R\x05price\x12\x1a\n" +
+	"\brepriced\x18\x05 \x01(\bR\brepriced\x12\x19\n" +
+	"\border_id\x18\x06 \x01(\x04R\aorderId\x124\n" +
+	"\vexec_status\x18\a \x01(\x0e2\x13.loki.pb.ExecStatusR\n" +
+	"execStatus\x12\x1d\n" +
+	"\n" +
+	"filled_qty\x18\b \x01(\x03R\tfilledQty\x12#\n" +
+	"\rremaining_qty\x18\t \x01(\x03R\fremainingQty\x12\x1b\n" +
+	"\tavg_price\x18\n" +
+	" \x01(\x01R\bavgPrice\x12#\n" +
+	"\x05fills\x18\v \x03(\v2\r.loki.pb.FillR\x05fills\"I\n" +
+	"\x04Fill\x12\x19\n" +
+	"\bmaker_id\x18\x01 \x01(\x04R\amakerId\x12\x14\n" +
+	"\x05price\x18\x02 \x01(\x03R\x05price\x12\x10\n" +
+	"\x03qty\x18\x03 \x01(\x03R\x03qty\"\x88\x01\n" +
 	"\x12CancelOrderRequest\x12\x19\n" +
-	"\border_id\x18\x01 \x01(\x04R\aorderId\x12!\n" +
-	"\x04side\x18\x02 \x01(\x0e2\r.loki.pb.SideR\x04side\x12\x14\n" +
-	"\x05price\x18\x03 \x01(\x03R\x05price\"-\n" +
+	"\border_id\x18\x01 \x01(\x04R\aorderId\x12%\n" +
+	"\x04side\x18\x02 \x01(\x0e2\r.loki.pb.SideB\x02\x18\x01R\x04side\x12\x18\n" +
+	"\x05price\x18\x03 \x01(\x03B\x02\x18\x01R\x05price\x12\x16\n" +
+	"\x06symbol\x18\x04 \x01(\tR\x06symbol\"D\n" +
 	"\x13CancelOrderResponse\x12\x16\n" +
-	"\x06status\x18\x01 \x01(\tR\x06status\"\x11\n" +
-	"\x0fSnapshotRequest\"\x8f\x01\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x15\n" +
+	"\x06seq_id\x18\x02 \x01(\x04R\x05seqId\"n\n" +
+	"\x11AmendOrderRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x04R\aorderId\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x12\x14\n" +
+	"\x05price\x18\x03 \x01(\x03R\x05price\x12\x10\n" +
+	"\x03qty\x18\x04 \x01(\x03R\x03qty\"D\n" +
+	"\x0fGetOrderRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x04R\aorderId\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\"U\n" +
+	"\x10GetOrderResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12)\n" +
+	"\x05order\x18\x02 \x01(\v2\x13.loki.pb.OrderEntryR\x05order\")\n" +
+	"\x0fSnapshotRequest\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\"\xbf\x01\n" +
 	"\n" +
 	"OrderEntry\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x04R\x02id\x12!\n" +
 	"\x04side\x18\x02 \x01(\x0e2\r.loki.pb.SideR\x04side\x12&\n" +
 	"\x04type\x18\x03 \x01(\x0e2\x12.loki.pb.OrderTypeR\x04type\x12\x14\n" +
 	"\x05price\x18\x04 \x01(\x03R\x05price\x12\x10\n" +
-	"\x03qty\x18\x05 \x01(\x03R\x03qty\"?\n" +
+	"\x03qty\x18\x05 \x01(\x03R\x03qty\x12\x16\n" +
+	"\x06filled\x18\x06 \x01(\x03R\x06filled\x12\x16\n" +
+	"\x06symbol\x18\a \x01(\tR\x06symbol\"?\n" +
 	"\x10SnapshotResponse\x12+\n" +
-	"\x06orders\x18\x01 \x03(\v2\x13.loki.pb.OrderEntryR\x06orders*.\n" +
+	"\x06orders\x18\x01 \x03(\v2\x13.loki.pb.OrderEntryR\x06orders\"\x8d\x02\n" +
+	"\n" +
+	"Instrument\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12\x1b\n" +
+	"\ttick_size\x18\x02 \x01(\x03R\btickSize\x124\n" +
+	"\tpost_only\x18\x03 \x01(\x0e2\x17.loki.pb.PostOnlyPolicyR\bpostOnly\x12\x19\n" +
+	"\blot_size\x18\x04 \x01(\x03R\alotSize\x12\x17\n" +
+	"\amin_qty\x18\x05 \x01(\x03R\x06minQty\x12\x17\n" +
+	"\amax_qty\x18\x06 \x01(\x03R\x06maxQty\x12!\n" +
+	"\fmin_notional\x18\a \x01(\x03R\vminNotional\x12$\n" +
+	"\x0eprice_band_bps\x18\b \x01(\x03R\fpriceBandBps\"\x18\n" +
+	"\x16ListInstrumentsRequest\"P\n" +
+	"\x17ListInstrumentsResponse\x125\n" +
+	"\vinstruments\x18\x01 \x03(\v2\x13.loki.pb.InstrumentR\vinstruments\"N\n" +
+	"\x17CreateInstrumentRequest\x123\n" +
+	"\n" +
+	"instrument\x18\x01 \x01(\v2\x13.loki.pb.InstrumentR\n" +
+	"instrument\"I\n" +
+	"\x18CreateInstrumentResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x15\n" +
+	"\x06seq_id\x18\x02 \x01(\x04R\x05seqId*.\n" +
 	"\x04Side\x12\x14\n" +
 	"\x10SIDE_UNSPECIFIED\x10\x00\x12\a\n" +
 	"\x03BID\x10\x01\x12\a\n" +
@@ -557,12 +1362,30 @@ const file_api_pb_order_proto_rawDesc = "" +
 	"\x06MARKET\x10\x02\x12\a\n" +
 	"\x03IOC\x10\x03\x12\a\n" +
 	"\x03FOK\x10\x04\x12\r\n" +
-	"\tPOST_ONLY\x10\x052\xe3\x01\n" +
+	"\tPOST_ONLY\x10\x05*;\n" +
+	"\x0ePostOnlyPolicy\x12\x14\n" +
+	"\x10POST_ONLY_REJECT\x10\x00\x12\x13\n" +
+	"\x0fPOST_ONLY_SLIDE\x10\x01*t\n" +
+	"\n" +
+	"ExecStatus\x12\x1b\n" +
+	"\x17EXEC_STATUS_UNSPECIFIED\x10\x00\x12\v\n" +
+	"\aRESTING\x10\x01\x12\x14\n" +
+	"\x10PARTIALLY_FILLED\x10\x02\x12\n" +
+	"\n" +
+	"\x06FILLED\x10\x03\x12\f\n" +
+	"\bCANCELED\x10\x04\x12\f\n" +
+	"\bREJECTED\x10\x052\xeb\x02\n" +
 	"\fOrderService\x12E\n" +
 	"\n" +
 	"PlaceOrder\x12\x1a.loki.pb.PlaceOrderRequest\x1a\x1b.loki.pb.PlaceOrderResponse\x12H\n" +
-	"\vCancelOrder\x12\x1b.loki.pb.CancelOrderRequest\x1a\x1c.loki.pb.CancelOrderResponse\x12B\n" +
-	"\vGetSnapshot\x12\x18.loki.pb.SnapshotRequest\x1a\x19.loki.pb.SnapshotResponseB\vZ\tapi/pb;pbb\x06proto3"
+	"\vCancelOrder\x12\x1b.loki.pb.CancelOrderRequest\x1a\x1c.loki.pb.CancelOrderResponse\x12E\n" +
+	"\n" +
+	"AmendOrder\x12\x1a.loki.pb.AmendOrderRequest\x1a\x1b.loki.pb.PlaceOrderResponse\x12?\n" +
+	"\bGetOrder\x12\x18.loki.pb.GetOrderRequest\x1a\x19.loki.pb.GetOrderResponse\x12B\n" +
+	"\vGetSnapshot\x12\x18.loki.pb.SnapshotRequest\x1a\x19.loki.pb.SnapshotResponse2\xbd\x01\n" +
+	"\fAdminService\x12T\n" +
+	"\x0fListInstruments\x12\x1f.loki.pb.ListInstrumentsRequest\x1a .loki.pb.ListInstrumentsResponse\x12W\n" +
+	"\x10CreateInstrument\x12 .loki.pb.CreateInstrumentRequest\x1a!.loki.pb.CreateInstrumentResponseB\vZ\tapi/pb;pbb\x06proto3"
 
 var (
 	file_api_pb_order_proto_rawDescOnce sync.Once
@@ -576,37 +1399,62 @@ func file_api_pb_order_proto_rawDescGZIP() []byte {
 	return file_api_pb_order_proto_rawDescData
 }
 
-var file_api_pb_order_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_api_pb_order_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_api_pb_order_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_api_pb_order_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
 var file_api_pb_order_proto_goTypes = []any{
-	(Side)(0),                   // 0: loki.pb.Side
-	(OrderType)(0),              // 1: loki.pb.OrderType
-	(*PlaceOrderRequest)(nil),   // 2: loki.pb.PlaceOrderRequest
-	(*PlaceOrderResponse)(nil),  // 3: loki.pb.PlaceOrderResponse
-	(*CancelOrderRequest)(nil),  // 4: loki.pb.CancelOrderRequest
-	(*CancelOrderResponse)(nil), // 5: loki.pb.CancelOrderResponse
-	(*SnapshotRequest)(nil),     // 6: loki.pb.SnapshotRequest
-	(*OrderEntry)(nil),          // 7: loki.pb.OrderEntry
-	(*SnapshotResponse)(nil),    // 8: loki.pb.SnapshotResponse
+	(Side)(0),                        // 0: loki.pb.Side
+	(OrderType)(0),                   // 1: loki.pb.OrderType
+	(PostOnlyPolicy)(0),              // 2: loki.pb.PostOnlyPolicy
+	(ExecStatus)(0),                  // 3: loki.pb.ExecStatus
+	(*PlaceOrderRequest)(nil),        // 4: loki.pb.PlaceOrderRequest
+	(*PlaceOrderResponse)(nil),       // 5: loki.pb.PlaceOrderResponse
+	(*Fill)(nil),                     // 6: loki.pb.Fill
+	(*CancelOrderRequest)(nil),       // 7: loki.pb.CancelOrderRequest
+	(*CancelOrderResponse)(nil),      // 8: loki.pb.CancelOrderResponse
+	(*AmendOrderRequest)(nil),        // 9: loki.pb.AmendOrderRequest
+	(*GetOrderRequest)(nil),          // 10: loki.pb.GetOrderRequest
+	(*GetOrderResponse)(nil),         // 11: loki.pb.GetOrderResponse
+	(*SnapshotRequest)(nil),          // 12: loki.pb.SnapshotRequest
+	(*OrderEntry)(nil),               // 13: loki.pb.OrderEntry
+	(*SnapshotResponse)(nil),         // 14: loki.pb.SnapshotResponse
+	(*Instrument)(nil),               // 15: loki.pb.Instrument
+	(*ListInstrumentsRequest)(nil),   // 16: loki.pb.ListInstrumentsRequest
+	(*ListInstrumentsResponse)(nil),  // 17: loki.pb.ListInstrumentsResponse
+	(*CreateInstrumentRequest)(nil),  // 18: loki.pb.CreateInstrumentRequest
+	(*CreateInstrumentResponse)(nil), // 19: loki.pb.CreateInstrumentResponse
 }
 var file_api_pb_order_proto_depIdxs = []int32{
-	0, // 0: loki.pb.PlaceOrderRequest.side:type_name -> loki.pb.Side
-	1, // 1: loki.pb.PlaceOrderRequest.type:type_name -> loki.pb.OrderType
-	0, // 2: loki.pb.CancelOrderRequest.side:type_name -> loki.pb.Side
-	0, // 3: loki.pb.OrderEntry.side:type_name -> loki.pb.Side
-	1, // 4: loki.pb.OrderEntry.type:type_name -> loki.pb.OrderType
-	7, // 5: loki.pb.SnapshotResponse.orders:type_name -> loki.pb.OrderEntry
-	2, // 6: loki.pb.OrderService.PlaceOrder:input_type -> loki.pb.PlaceOrderRequest
-	4, // 7: loki.pb.OrderService.CancelOrder:input_type -> loki.pb.CancelOrderRequest
-	6, // 8: loki.pb.OrderService.GetSnapshot:input_type -> loki.pb.SnapshotRequest
-	3, // 9: loki.pb.OrderService.PlaceOrder:output_type -> loki.pb.PlaceOrderResponse
-	5, // 10: loki.pb.OrderService.CancelOrder:output_type -> loki.pb.CancelOrderResponse
-	8, // 11: loki.pb.OrderService.GetSnapshot:output_type -> loki.pb.SnapshotResponse
-	9, // [9:12] is the sub-list for method output_type
-	6, // [6:9] is the sub-list for method input_type
-	6, // [6:6] is the sub-list for extension type_name
-	6, // [6:6] is the sub-list for extension extendee
-	0, // [0:6] is the sub-list for field type_name
+	0,  // 0: loki.pb.PlaceOrderRequest.side:type_name -> loki.pb.Side
+	1,  // 1: loki.pb.PlaceOrderRequest.type:type_name -> loki.pb.OrderType
+	3,  // 2: loki.pb.PlaceOrderResponse.exec_status:type_name -> loki.pb.ExecStatus
+	6,  // 3: loki.pb.PlaceOrderResponse.fills:type_name -> loki.pb.Fill
+	0,  // 4: loki.pb.CancelOrderRequest.side:type_name -> loki.pb.Side
+	13, // 5: loki.pb.GetOrderResponse.order:type_name -> loki.pb.OrderEntry
+	0,  // 6: loki.pb.OrderEntry.side:type_name -> loki.pb.Side
+	1,  // 7: loki.pb.OrderEntry.type:type_name -> loki.pb.OrderType
+	13, // 8: loki.pb.SnapshotResponse.orders:type_name -> loki.pb.OrderEntry
+	2,  // 9: loki.pb.Instrument.post_only:type_name -> loki.pb.PostOnlyPolicy
+	15, // 10: loki.pb.ListInstrumentsResponse.instruments:type_name -> loki.pb.Instrument
+	15, // 11: loki.pb.CreateInstrumentRequest.instrument:type_name -> loki.pb.Instrument
+	4,  // 12: loki.pb.OrderService.PlaceOrder:input_type -> loki.pb.PlaceOrderRequest
+	7,  // 13: loki.pb.OrderService.CancelOrder:input_type -> loki.pb.CancelOrderRequest
+	9,  // 14: loki.pb.OrderService.AmendOrder:input_type -> loki.pb.AmendOrderRequest
+	10, // 15: loki.pb.OrderService.GetOrder:input_type -> loki.pb.GetOrderRequest
+	12, // 16: loki.pb.OrderService.GetSnapshot:input_type -> loki.pb.SnapshotRequest
+	16, // 17: loki.pb.AdminService.ListInstruments:input_type -> loki.pb.ListInstrumentsRequest
+	18, // 18: loki.pb.AdminService.CreateInstrument:input_type -> loki.pb.CreateInstrumentRequest
+	5,  // 19: loki.pb.OrderService.PlaceOrder:output_type -> loki.pb.PlaceOrderResponse
+	8,  // 20: loki.pb.OrderService.CancelOrder:output_type -> loki.pb.CancelOrderResponse
+	5,  // 21: loki.pb.OrderService.AmendOrder:output_type -> loki.pb.PlaceOrderResponse
+	11, // 22: loki.pb.OrderService.GetOrder:output_type -> loki.pb.GetOrderResponse
+	14, // 23: loki.pb.OrderService.GetSnapshot:output_type -> loki.pb.SnapshotResponse
+	17, // 24: loki.pb.AdminService.ListInstruments:output_type -> loki.pb.ListInstrumentsResponse
+	19, // 25: loki.pb.AdminService.CreateInstrument:output_type -> loki.pb.CreateInstrumentResponse
+	19, // [19:26] is the sub-list for method output_type
+	12, // [12:19] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
 }
 
 func init() { file_api_pb_order_proto_init() }
@@ -619,10 +1467,10 @@ func file_api_pb_order_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_pb_order_proto_rawDesc), len(file_api_pb_order_proto_rawDesc)),
-			NumEnums:      2,
-			NumMessages:   7,
+			NumEnums:      4,
+			NumMessages:   16,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_api_pb_order_proto_goTypes,
 		DependencyIndexes: file_api_pb_order_proto_depIdxs,
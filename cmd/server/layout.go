@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/*
+On-disk layout migration.
+
+Before sharding every data directory (entry WAL, exit WAL,
+snapshots) held its files directly under its base path. Each
+shard now owns base/shard-NN. Everything from the flat layout
+belonged to the default instrument, so it is adopted by the
+shard DefaultSymbol routes to.
+
+Files are first moved into a staging directory that is renamed
+into place last, so a crash part-way through is finished on the
+next start instead of leaving two half-layouts behind.
+*/
+
+// adoptFlatLayout moves pre-sharding files in base into shard's
+// directory. It refuses to run if that directory already holds
+// data, rather than guess which copy is current.
+func adoptFlatLayout(base string, shard int) error {
+	entries, err := os.ReadDir(base)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	dst := shardDir(base, shard)
+	staging := filepath.Join(base, "."+filepath.Base(dst)+".migrating")
+
+	var legacy []string
+	for _, e := range entries {
+		name := e.Name()
+		if name == filepath.Base(staging) || (e.IsDir() && strings.HasPrefix(name, "shard-")) {
+			continue
+		}
+		legacy = append(legacy, name)
+	}
+
+	staged, err := exists(staging)
+	if err != nil {
+		return err
+	}
+	if len(legacy) == 0 && !staged {
+		return nil
+	}
+
+	if n, err := countEntries(dst); err != nil {
+		return err
+	} else if n > 0 {
+		return fmt.Errorf("%s holds data from before sharding but %s is not empty; move one of them aside", base, dst)
+	}
+
+	// 1️⃣ stage
+	if err := os.MkdirAll(staging, 0o755); err != nil {
+		return err
+	}
+	for _, name := range legacy {
+		if err := os.Rename(filepath.Join(base, name), filepath.Join(staging, name)); err != nil {
+			return err
+		}
+	}
+
+	// 2️⃣ swap in (an empty dst may exist from an earlier start)
+	if err := os.Remove(dst); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.Rename(staging, dst); err != nil {
+		return err
+	}
+	if err := syncDir(base); err != nil {
+		return err
+	}
+
+	log.Printf("layout: moved %d pre-sharding entries from %s into %s", len(legacy), base, dst)
+	return nil
+}
+
+func exists(path string) (bool, error) {
+	_, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func countEntries(dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	return len(entries), err
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
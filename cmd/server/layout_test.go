@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	entrywal "loki/infra/wal/entry"
+)
+
+func TestAdoptFlatLayout(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "entry")
+
+	// A pre-sharding entry WAL with one record.
+	w, err := entrywal.Open(entrywal.Config{Dir: base, SegmentSize: 1 << 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Append(entrywal.NewRecord(entrywal.RecordPlace, 1, []byte("1|0|0|100|5"))); err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Close()
+
+	if err := adoptFlatLayout(base, 2); err != nil {
+		t.Fatal(err)
+	}
+	if segs, _ := filepath.Glob(filepath.Join(base, "segment-*.wal")); len(segs) != 0 {
+		t.Fatalf("flat segments left behind: %v", segs)
+	}
+
+	w, err = entrywal.Open(entrywal.Config{Dir: shardDir(base, 2), SegmentSize: 1 << 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := w.LastSeq(); got != 1 {
+		t.Fatalf("adopted WAL at seq %d, want 1", got)
+	}
+	_ = w.Close()
+
+	// Nothing left to adopt: a second start is a no-op.
+	if err := adoptFlatLayout(base, 2); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAdoptFlatLayoutRefusesConflict(t *testing.T) {
+	base := t.TempDir()
+	if err := os.WriteFile(filepath.Join(base, "snapshot.bin"), []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(shardDir(base, 0), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(shardDir(base, 0), "snapshot.bin"), []byte("new"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := adoptFlatLayout(base, 0); err == nil {
+		t.Fatal("adopted flat data over a populated shard")
+	}
+}
+
+func TestAdoptFlatLayoutResumesStaging(t *testing.T) {
+	base := t.TempDir()
+	staging := filepath.Join(base, ".shard-01.migrating")
+	if err := os.MkdirAll(staging, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(staging, "MANIFEST"), []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(base, "segment-000001.wal"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := adoptFlatLayout(base, 1); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"MANIFEST", "segment-000001.wal"} {
+		if _, err := os.Stat(filepath.Join(shardDir(base, 1), name)); err != nil {
+			t.Fatalf("%s not adopted: %v", name, err)
+		}
+	}
+}
@@ -2,10 +2,12 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
@@ -26,13 +28,123 @@ import (
 	"loki/snapshot"
 )
 
+// shardCount is part of the on-disk layout (symbol → shard →
+// WAL directory). Do not change it once data exists.
+const shardCount = 4
+
+// dataDirs are the per-shard data roots, see shardDir.
+var dataDirs = []string{
+	"./data/wal/entry",
+	"./data/wal/exit",
+	"./data/snapshots",
+}
+
 func main() {
 	log.Println("starting loki engine")
 
+	// -----------------------------
+	// Shards (one writer each)
+	// -----------------------------
+	defaultShard := service.ShardIndex(orderbook.DefaultSymbol, shardCount)
+	for _, base := range dataDirs {
+		if err := adoptFlatLayout(base, defaultShard); err != nil {
+			log.Fatalf("data layout: %v", err)
+		}
+	}
+
+	svcs := make([]*service.OrderService, shardCount)
+	exitWALs := make([]*exitwal.ExitWAL, shardCount)
+
+	for i := range shardCount {
+		svcs[i], exitWALs[i] = openShard(i)
+	}
+
+	engine := service.NewEngine(svcs...)
+	defer engine.Close()
+
+	// -----------------------------
+	// Snapshot job (METHOD, not function)
+	// -----------------------------
+	for i, svc := range svcs {
+		svc.StartSnapshotJob(service.SnapshotJobConfig{
+			Dir:         shardDir("./data/snapshots", i),
+			Interval:    5 * time.Second,
+			Retain:      3,
+			TruncateLag: 10_000,
+		})
+	}
+
+	// -----------------------------
+	// Broadcaster jobs (own Kafka)
+	// -----------------------------
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for _, exitWAL := range exitWALs {
+		b, err := broadcaster.New(
+			exitWAL,
+			[]string{"localhost:29092"},
+			"orders",
+		)
+		if err != nil {
+			log.Fatalf("broadcaster init failed: %v", err)
+		}
+		b.Start(ctx)
+	}
+
+	// -----------------------------
+	// gRPC server
+	// -----------------------------
+	grpcSrv := grpc.NewServer()
+	pb.RegisterOrderServiceServer(
+		grpcSrv,
+		grpcserver.NewServer(engine),
+	)
+	pb.RegisterAdminServiceServer(
+		grpcSrv,
+		grpcserver.NewAdminServer(engine),
+	)
+
+	lis, err := net.Listen("tcp", ":50051")
+	if err != nil {
+		log.Fatalf("listen failed: %v", err)
+	}
+
+	go func() {
+		log.Println("loki engine running on :50051")
+		if err := grpcSrv.Serve(lis); err != nil {
+			log.Fatalf("grpc serve failed: %v", err)
+		}
+	}()
+
+	// -----------------------------
+	// Graceful shutdown
+	// -----------------------------
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
+	<-ch
+
+	log.Println("shutting down")
+	grpcSrv.GracefulStop()
+}
+
+// openShard wires and replays everything one shard owns.
+func openShard(i int) (*service.OrderService, *exitwal.ExitWAL) {
 	// -----------------------------
 	// Domain
 	// -----------------------------
-	book := orderbook.NewOrderBook()
+	// The default instrument is static: it is recreated on every
+	// start, before replay. Others are journaled via AdminService.
+	books := orderbook.NewRegistry()
+	if service.ShardIndex(orderbook.DefaultSymbol, shardCount) == i {
+		if _, err := books.Create(orderbook.Instrument{
+			Symbol:   orderbook.DefaultSymbol,
+			TickSize: 1,
+			PostOnly: orderbook.PostOnlyReject,
+		}); err != nil {
+			log.Fatalf("default instrument: %v", err)
+		}
+	}
 
 	// -----------------------------
 	// Memory (REAL API)
@@ -43,7 +155,7 @@ func main() {
 	ring := memory.NewRetireRing(2048)
 
 	// -----------------------------
-	// Sequencer
+	// Sequencer (per shard)
 	// -----------------------------
 	seqGen := sequence.New(0)
 
@@ -55,36 +167,42 @@ func main() {
 	// -----------------------------
 	// WALs
 	// -----------------------------
+	entryDir := shardDir("./data/wal/entry", i)
+
 	entryWAL, err := entrywal.Open(entrywal.Config{
-		Dir:         "./data/wal/entry",
-		SegmentSize: 64 << 20,
+		Dir:             entryDir,
+		SegmentSize:     64 << 20,
+		SegmentDuration: time.Hour,
+		// The shard writer is the only appender and already
+		// journals a whole batch per AppendBatch, so there is
+		// nothing left for group commit to merge.
+		Durability: entrywal.SyncEveryRecord,
 	})
 	if err != nil {
-		log.Fatalf("entry WAL open failed: %v", err)
+		log.Fatalf("shard %d: entry WAL open failed: %v", i, err)
 	}
 
-	exitWAL, err := exitwal.Open("./data/wal/exit")
+	exitWAL, err := exitwal.Open(shardDir("./data/wal/exit", i))
 	if err != nil {
-		log.Fatalf("exit WAL open failed: %v", err)
+		log.Fatalf("shard %d: exit WAL open failed: %v", i, err)
 	}
 
 	// -----------------------------
-	// Replay BEFORE serving
+	// Recover BEFORE serving
 	// -----------------------------
-	if err := service.ReplayFromWAL(
-		"./data/wal/entry",
-		book,
+	if err := service.Recover(
+		shardDir("./data/snapshots", i),
+		entryDir,
+		books,
 		pool,
 		seqGen,
 	); err != nil {
-		log.Fatalf("WAL replay failed: %v", err)
+		log.Fatalf("shard %d: recovery failed: %v", i, err)
 	}
 
-	// -----------------------------
-	// Core service
-	// -----------------------------
-	orderSvc := service.NewOrderService(
-		book,
+	svc := service.NewOrderService(
+		i,
+		books,
 		pool,
 		ring,
 		snapReader,
@@ -92,58 +210,9 @@ func main() {
 		entryWAL,
 		exitWAL,
 	)
+	return svc, exitWAL
+}
 
-	// -----------------------------
-	// Snapshot job (METHOD, not function)
-	// -----------------------------
-	orderSvc.StartSnapshotJob(
-		"./data/snapshots",
-		5*time.Second,
-	)
-
-	// -----------------------------
-	// Broadcaster job (owns Kafka)
-	// -----------------------------
-	b, err := broadcaster.New(
-		exitWAL,
-		[]string{"localhost:29092"},
-		"orders",
-	)
-	if err != nil {
-		log.Fatalf("broadcaster init failed: %v", err)
-	}
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-	b.Start(ctx)
-
-	// -----------------------------
-	// gRPC server
-	// -----------------------------
-	grpcSrv := grpc.NewServer()
-	pb.RegisterOrderServiceServer(
-		grpcSrv,
-		grpcserver.NewServer(orderSvc),
-	)
-
-	lis, err := net.Listen("tcp", ":50051")
-	if err != nil {
-		log.Fatalf("listen failed: %v", err)
-	}
-
-	go func() {
-		log.Println("loki engine running on :50051")
-		if err := grpcSrv.Serve(lis); err != nil {
-			log.Fatalf("grpc serve failed: %v", err)
-		}
-	}()
-
-	// -----------------------------
-	// Graceful shutdown
-	// -----------------------------
-	ch := make(chan os.Signal, 1)
-	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
-	<-ch
-
-	log.Println("shutting down")
-	grpcSrv.GracefulStop()
+func shardDir(base string, i int) string {
+	return filepath.Join(base, fmt.Sprintf("shard-%02d", i))
 }
@@ -0,0 +1,43 @@
+package orderbook
+
+import "errors"
+
+var (
+	ErrOrderNotFound = errors.New("orderbook: order not found")
+	ErrOrderFilled   = errors.New("orderbook: order already filled")
+
+	// ErrDuplicateOrder rejects restoring an order whose ID is
+	// already resting in the book.
+	ErrDuplicateOrder = errors.New("orderbook: duplicate order")
+
+	// ErrFOKNotFillable rejects a fill-or-kill order whose full
+	// quantity is not available within its limit price.
+	ErrFOKNotFillable = errors.New("orderbook: FOK order not fillable")
+
+	// ErrPostOnlyWouldCross rejects a post-only order that would
+	// take liquidity under the PostOnlyReject policy.
+	ErrPostOnlyWouldCross = errors.New("orderbook: post-only order would cross")
+
+	// ErrInvalidAmend rejects an amend to a non-positive price
+	// or to a quantity that is not above what already filled.
+	ErrInvalidAmend = errors.New("orderbook: invalid amend")
+
+	// Order rule violations, see Rules.
+	ErrInvalidSide   = errors.New("orderbook: invalid side")
+	ErrInvalidType   = errors.New("orderbook: invalid order type")
+	ErrInvalidQty    = errors.New("orderbook: quantity must be positive")
+	ErrInvalidPrice  = errors.New("orderbook: invalid price for order type")
+	ErrTickSize      = errors.New("orderbook: price not a multiple of tick size")
+	ErrLotSize       = errors.New("orderbook: quantity not a multiple of lot size")
+	ErrQtyOutOfRange = errors.New("orderbook: quantity outside min/max")
+	ErrMinNotional   = errors.New("orderbook: notional below minimum")
+	ErrPriceBand     = errors.New("orderbook: price outside band")
+
+	// ErrInvalidInstrument rejects an instrument whose tick size
+	// or rules are negative or inconsistent.
+	ErrInvalidInstrument = errors.New("orderbook: invalid instrument")
+
+	ErrInvalidSymbol    = errors.New("orderbook: invalid symbol")
+	ErrInstrumentExists = errors.New("orderbook: instrument already exists")
+	ErrUnknownSymbol    = errors.New("orderbook: unknown symbol")
+)
@@ -0,0 +1,53 @@
+package orderbook
+
+// Instrument holds the static trading parameters of one symbol.
+type Instrument struct {
+	Symbol   string
+	TickSize int64
+	PostOnly PostOnlyPolicy
+	Rules    Rules
+}
+
+// Rules are the per-instrument limits every order is checked
+// against before it touches the book. A zero field is no limit.
+type Rules struct {
+	// LotSize is the quantity increment.
+	LotSize int64
+	MinQty  int64
+	MaxQty  int64
+
+	// MinNotional is the smallest price × qty accepted from a
+	// priced order. Market orders carry no price and skip it.
+	MinNotional int64
+
+	// PriceBandBps bounds how far, in basis points, a priced
+	// order may sit from the last trade. It applies once the
+	// book has traded.
+	PriceBandBps int64
+}
+
+// Validate checks what Registry.Create would refuse, so callers
+// can reject an instrument before journaling it.
+func (i Instrument) Validate() error {
+	if !ValidSymbol(i.Symbol) {
+		return ErrInvalidSymbol
+	}
+	if i.TickSize < 0 || !i.Rules.valid() {
+		return ErrInvalidInstrument
+	}
+	return nil
+}
+
+// valid reports whether r is usable: no negative limits and
+// MinQty not above MaxQty.
+func (r Rules) valid() bool {
+	if r.LotSize < 0 || r.MinQty < 0 || r.MaxQty < 0 ||
+		r.MinNotional < 0 || r.PriceBandBps < 0 {
+		return false
+	}
+	return r.MaxQty == 0 || r.MinQty <= r.MaxQty
+}
+
+// DefaultSymbol is used for requests and journal records that
+// predate multi-instrument support.
+const DefaultSymbol = "DEFAULT"
@@ -20,6 +20,7 @@ const (
 const (
 	Active Status = iota
 	Inactive
+	Rejected
 )
 
 // Order is a pure domain entity.
@@ -29,6 +30,7 @@ type Order struct {
 	Qty    int64
 	Filled int64
 	SeqID  uint64
+	UserID uint64
 
 	Side   Side
 	Type   OrderType
@@ -1,40 +1,306 @@
 package orderbook
 
-import "sync/atomic"
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// PostOnlyPolicy decides what happens to a post-only order
+// that would cross the spread.
+type PostOnlyPolicy int
+
+const (
+	// PostOnlyReject drops the order without touching the book.
+	PostOnlyReject PostOnlyPolicy = iota
+	// PostOnlySlide reprices the order one tick behind the
+	// best opposite price so it rests as a maker.
+	PostOnlySlide
+)
 
 // OrderBook is single-writer and deterministic.
 type OrderBook struct {
+	Symbol string
+
 	Bids *RBTree
 	Asks *RBTree
 
+	// PostOnly, TickSize and Rules must be set before the first
+	// Place and never change afterwards, or replay diverges.
+	PostOnly PostOnlyPolicy
+	TickSize int64
+	Rules    Rules
+
+	// LastPrice is the price of the most recent trade, the
+	// reference for Rules.PriceBandBps. 0 until the first trade.
+	LastPrice int64
+
+	// orders indexes every resting order by ID.
+	orders map[uint64]*Order
+
+	// filled remembers orders that recently left the book fully
+	// filled, so a late cancel gets ErrOrderFilled rather than
+	// ErrOrderNotFound. Not snapshotted: after a restart older
+	// IDs read as not found.
+	filled *recentIDs
+
+	// trades is reused across Place calls to avoid allocating.
+	trades []Trade
+
 	LastSeq atomic.Uint64
 }
 
 func NewOrderBook() *OrderBook {
 	return &OrderBook{
-		Bids: NewRBTree(),
-		Asks: NewRBTree(),
+		Bids:   NewRBTree(),
+		Asks:   NewRBTree(),
+		orders: make(map[uint64]*Order),
+		filled: newRecentIDs(filledMemory),
+	}
+}
+
+// Instrument returns the parameters this book was created with.
+func (b *OrderBook) Instrument() Instrument {
+	return Instrument{
+		Symbol:   b.Symbol,
+		TickSize: b.TickSize,
+		PostOnly: b.PostOnly,
+		Rules:    b.Rules,
 	}
 }
 
-func (b *OrderBook) Place(o *Order) {
+// Place matches o against the opposite side and rests any
+// remainder. The returned trades are only valid until the next call.
+//
+// A rejected order leaves the book untouched. Orders that break
+// the instrument's Rules are rejected before anything else.
+func (b *OrderBook) Place(o *Order) ([]Trade, error) {
 	b.LastSeq.Store(o.SeqID)
+	b.trades = b.trades[:0]
+
+	if err := b.check(o.Side, o.Type, o.Price, o.Qty); err != nil {
+		o.Status = Rejected
+		return nil, err
+	}
+
+	return b.place(o)
+}
+
+// PlaceLegacy is Place without the instrument's Rules, for
+// replaying records journaled before the rules existed. Those
+// orders were matched unchecked, so replay must match them the
+// same way.
+func (b *OrderBook) PlaceLegacy(o *Order) ([]Trade, error) {
+	b.LastSeq.Store(o.SeqID)
+	b.trades = b.trades[:0]
+
+	return b.place(o)
+}
+
+func (b *OrderBook) place(o *Order) ([]Trade, error) {
+	if o.Type == FOK && !b.canFill(o) {
+		o.Status = Rejected
+		return nil, ErrFOKNotFillable
+	}
+
+	if o.Type == PostOnly && b.crosses(o) {
+		if b.PostOnly != PostOnlySlide || !b.slide(o) {
+			o.Status = Rejected
+			return nil, ErrPostOnlyWouldCross
+		}
+	}
+
+	rests := o.Type == Limit || o.Type == PostOnly
 
 	if o.Side == Bid {
-		b.matchBid(o)
-		if o.Remaining() > 0 && o.Type == Limit {
+		if o.Type != PostOnly {
+			b.matchBid(o)
+		}
+		if o.Remaining() > 0 && rests {
 			b.Bids.GetOrCreate(o.Price).Enqueue(o)
+			b.orders[o.ID] = o
 		}
 	} else {
-		b.matchAsk(o)
-		if o.Remaining() > 0 && o.Type == Limit {
+		if o.Type != PostOnly {
+			b.matchAsk(o)
+		}
+		if o.Remaining() > 0 && rests {
 			b.Asks.GetOrCreate(o.Price).Enqueue(o)
+			b.orders[o.ID] = o
 		}
 	}
 
 	if o.Remaining() == 0 {
 		o.Status = Inactive
+		b.filled.add(o.ID)
+	}
+
+	return b.trades, nil
+}
+
+// canFill reports whether the opposite side holds enough
+// quantity within o's limit price to fill it completely.
+func (b *OrderBook) canFill(o *Order) bool {
+	want := o.Remaining()
+	if o.Side == Bid {
+		return b.Asks.DepthAsc(o.Price, want) >= want
+	}
+	return b.Bids.DepthDesc(o.Price, want) >= want
+}
+
+// Get returns the resting order with the given ID, or nil.
+func (b *OrderBook) Get(id uint64) *Order {
+	return b.orders[id]
+}
+
+// Cancel removes a resting order from its price level.
+// The returned order is detached and owned by the caller.
+func (b *OrderBook) Cancel(id uint64) (*Order, error) {
+	o := b.orders[id]
+	if o == nil {
+		return nil, b.missing(id)
+	}
+	if o.Remaining() == 0 || o.Status != Active {
+		return o, ErrOrderFilled
+	}
+
+	tree := b.Bids
+	if o.Side == Ask {
+		tree = b.Asks
+	}
+
+	lvl := tree.Find(o.Price)
+	lvl.Remove(o)
+	if lvl.Empty() {
+		tree.Delete(lvl.Price)
+	}
+	delete(b.orders, id)
+	o.Status = Inactive
+	return o, nil
+}
+
+// missing explains why id is not resting: ErrOrderFilled if it
+// filled recently, ErrOrderNotFound otherwise.
+func (b *OrderBook) missing(id uint64) error {
+	if b.filled.has(id) {
+		return ErrOrderFilled
+	}
+	return ErrOrderNotFound
+}
+
+// Restore puts a resting order back at the tail of its price
+// level as-is: no matching, no price checks, Filled and SeqID
+// kept. Restoring a level's orders in queue order rebuilds it
+// exactly, including FIFO priority.
+func (b *OrderBook) Restore(o *Order) error {
+	if b.orders[o.ID] != nil {
+		return ErrDuplicateOrder
+	}
+	if o.Remaining() <= 0 {
+		return ErrOrderFilled
+	}
+
+	tree := b.Bids
+	if o.Side == Ask {
+		tree = b.Asks
+	}
+
+	o.Status = Active
+	o.next, o.prev = nil, nil
+	tree.GetOrCreate(o.Price).Enqueue(o)
+	b.orders[o.ID] = o
+
+	if o.SeqID > b.LastSeq.Load() {
+		b.LastSeq.Store(o.SeqID)
+	}
+	return nil
+}
+
+// crosses reports whether o would take liquidity at its price.
+func (b *OrderBook) crosses(o *Order) bool {
+	if o.Side == Bid {
+		best := b.Asks.BestMin()
+		return best != nil && best.Price <= o.Price
+	}
+	best := b.Bids.BestMax()
+	return best != nil && best.Price >= o.Price
+}
+
+// slide moves o one tick behind the best opposite price.
+// It fails if that would leave a non-positive price.
+func (b *OrderBook) slide(o *Order) bool {
+	tick := b.TickSize
+	if tick <= 0 {
+		tick = 1
 	}
+
+	if o.Side == Bid {
+		price := b.Asks.BestMin().Price - tick
+		if price <= 0 {
+			return false
+		}
+		o.Price = price
+		return true
+	}
+
+	o.Price = b.Bids.BestMax().Price + tick
+	return true
+}
+
+// Amend changes the price and/or total quantity of a resting
+// order. qty is the new total, including what already filled.
+//
+// A quantity decrease at the same price keeps queue priority.
+// A price change or quantity increase pulls the order and
+// re-enters it through Place as of seq, keeping its ID.
+//
+// Every check Place would run happens first, so a rejected
+// amend changes nothing: one that breaks the instrument's Rules
+// fails with ErrInvalidAmend, wrapping the rule, and a post-only
+// order that would cross fails with ErrPostOnlyWouldCross.
+func (b *OrderBook) Amend(id, seq uint64, price, qty int64) (*Order, []Trade, error) {
+	o := b.orders[id]
+	if o == nil {
+		return nil, nil, b.missing(id)
+	}
+	if qty <= o.Filled || price <= 0 {
+		return o, nil, ErrInvalidAmend
+	}
+	if err := b.check(o.Side, o.Type, price, qty); err != nil {
+		return o, nil, fmt.Errorf("%w: %w", ErrInvalidAmend, err)
+	}
+	if o.Type == PostOnly {
+		probe := *o
+		probe.Price = price
+		if b.crosses(&probe) && (b.PostOnly != PostOnlySlide || !b.slide(&probe)) {
+			return o, nil, ErrPostOnlyWouldCross
+		}
+	}
+
+	tree := b.Bids
+	if o.Side == Ask {
+		tree = b.Asks
+	}
+	lvl := tree.Find(o.Price)
+
+	if price == o.Price && qty <= o.Qty {
+		lvl.TotalQty -= o.Qty - qty
+		o.Qty = qty
+		b.trades = b.trades[:0]
+		return o, b.trades, nil
+	}
+
+	lvl.Remove(o)
+	if lvl.Empty() {
+		tree.Delete(lvl.Price)
+	}
+	delete(b.orders, id)
+
+	o.Price = price
+	o.Qty = qty
+	o.SeqID = seq
+
+	trades, err := b.Place(o)
+	return o, trades, err
 }
 
 // ---- traversal helpers ----
@@ -64,10 +330,25 @@ func (b *OrderBook) matchBid(o *Order) {
 
 		o.Filled += trade
 		head.Filled += trade
+		best.TotalQty -= trade
+		b.LastPrice = best.Price
+		b.trades = append(b.trades, Trade{
+			Seq:       o.SeqID,
+			MakerID:   head.ID,
+			TakerID:   o.ID,
+			Price:     best.Price,
+			Qty:       trade,
+			Aggressor: o.Side,
+		})
 
 		if head.Remaining() == 0 {
 			head.Status = Inactive
 			best.PopHead()
+			delete(b.orders, head.ID)
+			b.filled.add(head.ID)
+			if best.Empty() {
+				b.Asks.Delete(best.Price)
+			}
 		}
 	}
 }
@@ -87,10 +368,25 @@ func (b *OrderBook) matchAsk(o *Order) {
 
 		o.Filled += trade
 		head.Filled += trade
+		best.TotalQty -= trade
+		b.LastPrice = best.Price
+		b.trades = append(b.trades, Trade{
+			Seq:       o.SeqID,
+			MakerID:   head.ID,
+			TakerID:   o.ID,
+			Price:     best.Price,
+			Qty:       trade,
+			Aggressor: o.Side,
+		})
 
 		if head.Remaining() == 0 {
 			head.Status = Inactive
 			best.PopHead()
+			delete(b.orders, head.ID)
+			b.filled.add(head.ID)
+			if best.Empty() {
+				b.Bids.Delete(best.Price)
+			}
 		}
 	}
 }
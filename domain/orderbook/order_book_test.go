@@ -0,0 +1,264 @@
+package orderbook
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOrderBookDropsEmptyLevels(t *testing.T) {
+	b := NewOrderBook()
+
+	b.Place(&Order{ID: 1, SeqID: 1, Side: Ask, Type: Limit, Price: 101, Qty: 5})
+	b.Place(&Order{ID: 2, SeqID: 2, Side: Ask, Type: Limit, Price: 102, Qty: 5})
+	b.Place(&Order{ID: 3, SeqID: 3, Side: Bid, Type: Limit, Price: 99, Qty: 5})
+
+	// Fully lifts 101 and partially 102.
+	trades, err := b.Place(&Order{ID: 4, SeqID: 4, Side: Bid, Type: IOC, Price: 102, Qty: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 2 || trades[0].MakerID != 1 || trades[1].Qty != 2 {
+		t.Fatalf("unexpected trades %+v", trades)
+	}
+	if b.Asks.Find(101) != nil {
+		t.Fatal("filled level 101 still in tree")
+	}
+	if lvl := b.Asks.Find(102); lvl == nil || lvl.TotalQty != 3 {
+		t.Fatalf("level 102 = %+v, want 3 remaining", lvl)
+	}
+
+	if _, err := b.Cancel(3); err != nil {
+		t.Fatal(err)
+	}
+	if b.Bids.BestMax() != nil {
+		t.Fatal("canceled level 99 still in tree")
+	}
+	if b.Get(3) != nil {
+		t.Fatal("canceled order still indexed")
+	}
+}
+
+func TestOrderBookAmendPriority(t *testing.T) {
+	b := NewOrderBook()
+
+	b.Place(&Order{ID: 1, SeqID: 1, Side: Bid, Type: Limit, Price: 100, Qty: 10})
+	b.Place(&Order{ID: 2, SeqID: 2, Side: Bid, Type: Limit, Price: 100, Qty: 10})
+
+	// Decrease keeps order 1 at the head.
+	if _, _, err := b.Amend(1, 3, 100, 4); err != nil {
+		t.Fatal(err)
+	}
+	lvl := b.Bids.Find(100)
+	if lvl.Head().ID != 1 || lvl.TotalQty != 14 {
+		t.Fatalf("head=%d total=%d, want head=1 total=14", lvl.Head().ID, lvl.TotalQty)
+	}
+
+	// Increase sends it to the back.
+	if _, _, err := b.Amend(1, 4, 100, 6); err != nil {
+		t.Fatal(err)
+	}
+	if lvl.Head().ID != 2 || lvl.Head().Next().ID != 1 || lvl.TotalQty != 16 {
+		t.Fatal("increase kept priority")
+	}
+
+	// A price change re-enters matching.
+	b.Place(&Order{ID: 5, SeqID: 5, Side: Ask, Type: Limit, Price: 105, Qty: 3})
+	o, trades, err := b.Amend(1, 6, 105, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 1 || trades[0].Qty != 3 || o.Remaining() != 3 || o.SeqID != 6 {
+		t.Fatalf("trades=%+v order=%+v", trades, o)
+	}
+	if b.Bids.Find(105).Head() != o {
+		t.Fatal("amended remainder not resting at new price")
+	}
+
+	if _, _, err := b.Amend(1, 7, 105, 3); err != ErrInvalidAmend {
+		t.Fatalf("amend to filled qty: err=%v", err)
+	}
+}
+
+func TestOrderBookRules(t *testing.T) {
+	b := NewOrderBook()
+	b.TickSize = 5
+	b.Rules = Rules{LotSize: 2, MinQty: 2, MaxQty: 100, MinNotional: 1000, PriceBandBps: 1000}
+
+	cases := []struct {
+		name string
+		o    Order
+		want error
+	}{
+		{"side", Order{Side: Side(7), Type: Limit, Price: 100, Qty: 10}, ErrInvalidSide},
+		{"type", Order{Side: Bid, Type: OrderType(9), Price: 100, Qty: 10}, ErrInvalidType},
+		{"zero qty", Order{Side: Bid, Type: Limit, Price: 100, Qty: 0}, ErrInvalidQty},
+		{"negative price", Order{Side: Bid, Type: Limit, Price: -100, Qty: 10}, ErrInvalidPrice},
+		{"priced market", Order{Side: Bid, Type: Market, Price: 100, Qty: 10}, ErrInvalidPrice},
+		{"tick", Order{Side: Bid, Type: Limit, Price: 101, Qty: 10}, ErrTickSize},
+		{"lot", Order{Side: Bid, Type: Limit, Price: 100, Qty: 11}, ErrLotSize},
+		{"max qty", Order{Side: Bid, Type: Limit, Price: 100, Qty: 102}, ErrQtyOutOfRange},
+		{"notional", Order{Side: Bid, Type: Limit, Price: 100, Qty: 8}, ErrMinNotional},
+		{"ok", Order{Side: Bid, Type: Limit, Price: 100, Qty: 10}, nil},
+	}
+	for i, c := range cases {
+		o := c.o
+		o.ID, o.SeqID = uint64(i+1), uint64(i+1)
+		if _, err := b.Place(&o); err != c.want {
+			t.Fatalf("%s: err = %v, want %v", c.name, err, c.want)
+		}
+		if c.want != nil && (o.Status != Rejected || b.Get(o.ID) != nil) {
+			t.Fatalf("%s: rejected order touched the book", c.name)
+		}
+	}
+
+	// No band before the first trade; after it, 10% around 100.
+	b.Place(&Order{ID: 20, SeqID: 20, Side: Ask, Type: Limit, Price: 100, Qty: 10})
+	if b.LastPrice != 100 {
+		t.Fatalf("LastPrice = %d, want 100", b.LastPrice)
+	}
+	if _, err := b.Place(&Order{ID: 21, SeqID: 21, Side: Ask, Type: Limit, Price: 115, Qty: 10}); err != ErrPriceBand {
+		t.Fatalf("err = %v, want ErrPriceBand", err)
+	}
+	if _, err := b.Place(&Order{ID: 22, SeqID: 22, Side: Ask, Type: Limit, Price: 110, Qty: 10}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A rule-breaking amend changes nothing.
+	if _, _, err := b.Amend(22, 23, 111, 10); !errors.Is(err, ErrInvalidAmend) || !errors.Is(err, ErrTickSize) {
+		t.Fatalf("err = %v, want ErrInvalidAmend wrapping ErrTickSize", err)
+	}
+	if o := b.Get(22); o == nil || o.Price != 110 {
+		t.Fatal("rejected amend moved the order")
+	}
+}
+
+func TestOrderBookRejectedAmendKeepsOrder(t *testing.T) {
+	b := NewOrderBook()
+
+	b.Place(&Order{ID: 1, SeqID: 1, Side: Ask, Type: Limit, Price: 105, Qty: 5})
+	b.Place(&Order{ID: 2, SeqID: 2, Side: Bid, Type: PostOnly, Price: 100, Qty: 5})
+	b.Place(&Order{ID: 3, SeqID: 3, Side: Bid, Type: Limit, Price: 100, Qty: 5})
+
+	if _, _, err := b.Amend(2, 4, 106, 5); err != ErrPostOnlyWouldCross {
+		t.Fatalf("err = %v, want ErrPostOnlyWouldCross", err)
+	}
+
+	o := b.Get(2)
+	if o == nil || o.Price != 100 || o.SeqID != 2 || o.Status != Active {
+		t.Fatalf("order after rejected amend: %+v", o)
+	}
+	if lvl := b.Bids.Find(100); lvl.Head().ID != 2 || lvl.TotalQty != 10 {
+		t.Fatal("rejected amend lost queue priority")
+	}
+	if b.Asks.Find(105).TotalQty != 5 {
+		t.Fatal("rejected amend traded")
+	}
+}
+
+func TestOrderBookCancelFilled(t *testing.T) {
+	b := NewOrderBook()
+
+	b.Place(&Order{ID: 1, SeqID: 1, Side: Ask, Type: Limit, Price: 100, Qty: 5})
+	b.Place(&Order{ID: 2, SeqID: 2, Side: Bid, Type: Limit, Price: 100, Qty: 5})
+
+	// maker and taker both filled
+	for _, id := range []uint64{1, 2} {
+		if _, err := b.Cancel(id); err != ErrOrderFilled {
+			t.Fatalf("cancel filled %d: err = %v, want ErrOrderFilled", id, err)
+		}
+		if _, _, err := b.Amend(id, 3, 101, 5); err != ErrOrderFilled {
+			t.Fatalf("amend filled %d: err = %v, want ErrOrderFilled", id, err)
+		}
+	}
+	if _, err := b.Cancel(42); err != ErrOrderNotFound {
+		t.Fatalf("cancel unknown: err = %v, want ErrOrderNotFound", err)
+	}
+}
+
+func TestOrderBookFOK(t *testing.T) {
+	b := NewOrderBook()
+	b.Place(&Order{ID: 1, SeqID: 1, Side: Ask, Type: Limit, Price: 100, Qty: 5})
+	b.Place(&Order{ID: 2, SeqID: 2, Side: Ask, Type: Limit, Price: 101, Qty: 5})
+	b.Place(&Order{ID: 3, SeqID: 3, Side: Ask, Type: Limit, Price: 102, Qty: 5})
+
+	// 12 are on offer up to 102 but only 10 up to 101: the limit
+	// price must hold, and a kill leaves every level as it was.
+	fok := &Order{ID: 4, SeqID: 4, Side: Bid, Type: FOK, Price: 101, Qty: 12}
+	if _, err := b.Place(fok); err != ErrFOKNotFillable || fok.Status != Rejected {
+		t.Fatalf("err = %v status = %v, want ErrFOKNotFillable", err, fok.Status)
+	}
+	for _, p := range []int64{100, 101, 102} {
+		if lvl := b.Asks.Find(p); lvl == nil || lvl.TotalQty != 5 {
+			t.Fatalf("level %d changed by a killed FOK", p)
+		}
+	}
+
+	// Fillable across three levels.
+	fok = &Order{ID: 5, SeqID: 5, Side: Bid, Type: FOK, Price: 102, Qty: 12}
+	trades, err := b.Place(fok)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 3 || trades[2].Price != 102 || trades[2].Qty != 2 || fok.Remaining() != 0 {
+		t.Fatalf("sweep trades %+v", trades)
+	}
+	if b.Asks.Find(100) != nil || b.Asks.Find(101) != nil || b.Asks.Find(102).TotalQty != 3 {
+		t.Fatal("sweep left the wrong levels")
+	}
+
+	// Sell side respects its limit too.
+	b.Place(&Order{ID: 6, SeqID: 6, Side: Bid, Type: Limit, Price: 99, Qty: 5})
+	if _, err := b.Place(&Order{ID: 7, SeqID: 7, Side: Ask, Type: FOK, Price: 100, Qty: 1}); err != ErrFOKNotFillable {
+		t.Fatalf("ask FOK above best bid: err = %v", err)
+	}
+}
+
+func TestOrderBookPostOnly(t *testing.T) {
+	b := NewOrderBook()
+	b.TickSize = 1
+	b.Place(&Order{ID: 1, SeqID: 1, Side: Ask, Type: Limit, Price: 105, Qty: 5})
+	b.Place(&Order{ID: 2, SeqID: 2, Side: Bid, Type: Limit, Price: 100, Qty: 5})
+
+	// Reject policy: a crossing post-only never trades or rests.
+	po := &Order{ID: 3, SeqID: 3, Side: Bid, Type: PostOnly, Price: 105, Qty: 1}
+	if _, err := b.Place(po); err != ErrPostOnlyWouldCross || po.Status != Rejected {
+		t.Fatalf("reject policy: err = %v status = %v", err, po.Status)
+	}
+	if b.Get(3) != nil || b.Asks.Find(105).TotalQty != 5 {
+		t.Fatal("rejected post-only touched the book")
+	}
+
+	// A non-crossing post-only rests at its own price.
+	if _, err := b.Place(&Order{ID: 4, SeqID: 4, Side: Bid, Type: PostOnly, Price: 104, Qty: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Slide policy: one tick behind the best opposite price.
+	b.PostOnly = PostOnlySlide
+	bid := &Order{ID: 5, SeqID: 5, Side: Bid, Type: PostOnly, Price: 110, Qty: 1}
+	ask := &Order{ID: 6, SeqID: 6, Side: Ask, Type: PostOnly, Price: 90, Qty: 1}
+	for _, o := range []*Order{bid, ask} {
+		if trades, err := b.Place(o); err != nil || len(trades) != 0 {
+			t.Fatalf("slide %d: trades %v err %v", o.ID, trades, err)
+		}
+	}
+	if bid.Price != 104 || ask.Price != 105 {
+		t.Fatalf("slid to bid %d ask %d, want 104 and 105", bid.Price, ask.Price)
+	}
+}
+
+func TestOrderBookPostOnlySlideFloor(t *testing.T) {
+	b := NewOrderBook()
+	b.TickSize = 1
+	b.PostOnly = PostOnlySlide
+	b.Place(&Order{ID: 1, SeqID: 1, Side: Ask, Type: Limit, Price: 1, Qty: 5})
+
+	// Sliding behind an ask at 1 would price the bid at 0.
+	po := &Order{ID: 2, SeqID: 2, Side: Bid, Type: PostOnly, Price: 3, Qty: 1}
+	if _, err := b.Place(po); err != ErrPostOnlyWouldCross {
+		t.Fatalf("err = %v, want ErrPostOnlyWouldCross", err)
+	}
+	if b.Bids.BestMax() != nil {
+		t.Fatal("slide to a non-positive price rested")
+	}
+}
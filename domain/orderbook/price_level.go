@@ -54,3 +54,24 @@ func (p *PriceLevel) Empty() bool {
 func (p *PriceLevel) Head() *Order {
 	return p.head
 }
+
+// Remove unlinks o from anywhere in the queue in O(1).
+// The caller must guarantee that o belongs to this level.
+func (p *PriceLevel) Remove(o *Order) {
+	if o.prev != nil {
+		o.prev.next = o.next
+	} else {
+		p.head = o.next
+	}
+	if o.next != nil {
+		o.next.prev = o.prev
+	} else {
+		p.tail = o.prev
+	}
+
+	o.next = nil
+	o.prev = nil
+
+	p.TotalQty -= o.Remaining()
+	p.OrderCount--
+}
@@ -6,6 +6,7 @@ type rbNode struct {
 	left   *rbNode
 	right  *rbNode
 	parent *rbNode
+	red    bool
 }
 
 type RBTree struct {
@@ -34,6 +35,17 @@ func (t *RBTree) GetOrCreate(price int64) *PriceLevel {
 	return lvl
 }
 
+// Delete removes the level at price. It reports whether a
+// level was present.
+func (t *RBTree) Delete(price int64) bool {
+	n := t.find(price)
+	if n == t.nil {
+		return false
+	}
+	t.delete(n)
+	return true
+}
+
 func (t *RBTree) Find(price int64) *PriceLevel {
 	n := t.find(price)
 	if n == t.nil {
@@ -58,6 +70,34 @@ func (t *RBTree) BestMax() *PriceLevel {
 	return n.level
 }
 
+// ---- depth ----
+
+// DepthAsc sums resting quantity from the lowest price up to
+// and including limit, stopping early once want is reached.
+// It never mutates the levels it walks.
+func (t *RBTree) DepthAsc(limit, want int64) int64 {
+	var total int64
+	for n := t.min(t.root); n != t.nil && n.key <= limit; n = t.next(n) {
+		total += n.level.TotalQty
+		if total >= want {
+			break
+		}
+	}
+	return total
+}
+
+// DepthDesc is DepthAsc walking from the highest price down.
+func (t *RBTree) DepthDesc(limit, want int64) int64 {
+	var total int64
+	for n := t.max(t.root); n != t.nil && n.key >= limit; n = t.prev(n) {
+		total += n.level.TotalQty
+		if total >= want {
+			break
+		}
+	}
+	return total
+}
+
 // ---- walkers ----
 
 func (t *RBTree) walkAsc(fn func(*PriceLevel)) {
@@ -126,8 +166,223 @@ func (t *RBTree) prev(n *rbNode) *rbNode {
 	return p
 }
 
-// NOTE: insert balancing logic should be pasted here from your
-// existing RB-tree implementation unchanged.
+// ---- balancing (CLRS) ----
+//
+// t.nil is a shared black sentinel. Its parent pointer is
+// scratch space used by deleteFixup and is never read otherwise.
+
 func (t *RBTree) insert(price int64, lvl *PriceLevel) {
-	// reuse your existing correct RB insert code here
+	z := &rbNode{
+		key:   price,
+		level: lvl,
+		left:  t.nil,
+		right: t.nil,
+		red:   true,
+	}
+
+	y := t.nil
+	x := t.root
+	for x != t.nil {
+		y = x
+		if z.key < x.key {
+			x = x.left
+		} else {
+			x = x.right
+		}
+	}
+
+	z.parent = y
+	switch {
+	case y == t.nil:
+		t.root = z
+	case z.key < y.key:
+		y.left = z
+	default:
+		y.right = z
+	}
+
+	t.insertFixup(z)
+}
+
+func (t *RBTree) insertFixup(z *rbNode) {
+	for z.parent.red {
+		gp := z.parent.parent
+		if z.parent == gp.left {
+			y := gp.right
+			if y.red {
+				z.parent.red = false
+				y.red = false
+				gp.red = true
+				z = gp
+				continue
+			}
+			if z == z.parent.right {
+				z = z.parent
+				t.rotateLeft(z)
+			}
+			z.parent.red = false
+			z.parent.parent.red = true
+			t.rotateRight(z.parent.parent)
+		} else {
+			y := gp.left
+			if y.red {
+				z.parent.red = false
+				y.red = false
+				gp.red = true
+				z = gp
+				continue
+			}
+			if z == z.parent.left {
+				z = z.parent
+				t.rotateRight(z)
+			}
+			z.parent.red = false
+			z.parent.parent.red = true
+			t.rotateLeft(z.parent.parent)
+		}
+	}
+	t.root.red = false
+}
+
+func (t *RBTree) delete(z *rbNode) {
+	y := z
+	yRed := y.red
+	var x *rbNode
+
+	switch {
+	case z.left == t.nil:
+		x = z.right
+		t.transplant(z, z.right)
+	case z.right == t.nil:
+		x = z.left
+		t.transplant(z, z.left)
+	default:
+		y = t.min(z.right)
+		yRed = y.red
+		x = y.right
+		if y.parent == z {
+			x.parent = y
+		} else {
+			t.transplant(y, y.right)
+			y.right = z.right
+			y.right.parent = y
+		}
+		t.transplant(z, y)
+		y.left = z.left
+		y.left.parent = y
+		y.red = z.red
+	}
+
+	if !yRed {
+		t.deleteFixup(x)
+	}
+
+	// Keep the sentinel clean and drop references for the GC.
+	t.nil.parent = t.nil
+	z.left, z.right, z.parent, z.level = nil, nil, nil, nil
+}
+
+func (t *RBTree) deleteFixup(x *rbNode) {
+	for x != t.root && !x.red {
+		if x == x.parent.left {
+			w := x.parent.right
+			if w.red {
+				w.red = false
+				x.parent.red = true
+				t.rotateLeft(x.parent)
+				w = x.parent.right
+			}
+			if !w.left.red && !w.right.red {
+				w.red = true
+				x = x.parent
+				continue
+			}
+			if !w.right.red {
+				w.left.red = false
+				w.red = true
+				t.rotateRight(w)
+				w = x.parent.right
+			}
+			w.red = x.parent.red
+			x.parent.red = false
+			w.right.red = false
+			t.rotateLeft(x.parent)
+			x = t.root
+		} else {
+			w := x.parent.left
+			if w.red {
+				w.red = false
+				x.parent.red = true
+				t.rotateRight(x.parent)
+				w = x.parent.left
+			}
+			if !w.right.red && !w.left.red {
+				w.red = true
+				x = x.parent
+				continue
+			}
+			if !w.left.red {
+				w.right.red = false
+				w.red = true
+				t.rotateLeft(w)
+				w = x.parent.left
+			}
+			w.red = x.parent.red
+			x.parent.red = false
+			w.left.red = false
+			t.rotateRight(x.parent)
+			x = t.root
+		}
+	}
+	x.red = false
+}
+
+func (t *RBTree) transplant(u, v *rbNode) {
+	switch {
+	case u.parent == t.nil:
+		t.root = v
+	case u == u.parent.left:
+		u.parent.left = v
+	default:
+		u.parent.right = v
+	}
+	v.parent = u.parent
+}
+
+func (t *RBTree) rotateLeft(x *rbNode) {
+	y := x.right
+	x.right = y.left
+	if y.left != t.nil {
+		y.left.parent = x
+	}
+	y.parent = x.parent
+	switch {
+	case x.parent == t.nil:
+		t.root = y
+	case x == x.parent.left:
+		x.parent.left = y
+	default:
+		x.parent.right = y
+	}
+	y.left = x
+	x.parent = y
+}
+
+func (t *RBTree) rotateRight(x *rbNode) {
+	y := x.left
+	x.left = y.right
+	if y.right != t.nil {
+		y.right.parent = x
+	}
+	y.parent = x.parent
+	switch {
+	case x.parent == t.nil:
+		t.root = y
+	case x == x.parent.right:
+		x.parent.right = y
+	default:
+		x.parent.left = y
+	}
+	y.right = x
+	x.parent = y
 }
@@ -0,0 +1,148 @@
+package orderbook
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// checkInvariants verifies the red-black properties, BST
+// ordering and parent links, and returns the in-order keys.
+func checkInvariants(t *testing.T, tr *RBTree) []int64 {
+	t.Helper()
+
+	if tr.root.red {
+		t.Fatal("root is red")
+	}
+	if tr.nil.red {
+		t.Fatal("sentinel is red")
+	}
+	if tr.root != tr.nil && tr.root.parent != tr.nil {
+		t.Fatal("root has a parent")
+	}
+
+	var keys []int64
+	var walk func(n *rbNode, lo, hi *int64) int
+	walk = func(n *rbNode, lo, hi *int64) int {
+		if n == tr.nil {
+			return 1
+		}
+		if lo != nil && n.key <= *lo || hi != nil && n.key >= *hi {
+			t.Fatalf("key %d violates BST order", n.key)
+		}
+		if n.level == nil || n.level.Price != n.key {
+			t.Fatalf("node %d has wrong level", n.key)
+		}
+		if n.red && (n.left.red || n.right.red) {
+			t.Fatalf("red node %d has a red child", n.key)
+		}
+		if n.left != tr.nil && n.left.parent != n ||
+			n.right != tr.nil && n.right.parent != n {
+			t.Fatalf("node %d has a broken parent link", n.key)
+		}
+
+		lh := walk(n.left, lo, &n.key)
+		keys = append(keys, n.key)
+		rh := walk(n.right, &n.key, hi)
+		if lh != rh {
+			t.Fatalf("black height differs under %d: %d vs %d", n.key, lh, rh)
+		}
+		if n.red {
+			return lh
+		}
+		return lh + 1
+	}
+	walk(tr.root, nil, nil)
+
+	return keys
+}
+
+func TestRBTreeRandomOps(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		tr := NewRBTree()
+		want := map[int64]bool{}
+
+		for i := 0; i < 2000; i++ {
+			price := rng.Int63n(500)
+			if rng.Intn(3) == 0 {
+				if got := tr.Delete(price); got != want[price] {
+					t.Fatalf("seed %d: Delete(%d) = %v, want %v", seed, price, got, want[price])
+				}
+				delete(want, price)
+			} else {
+				tr.GetOrCreate(price)
+				want[price] = true
+			}
+		}
+
+		keys := checkInvariants(t, tr)
+
+		expected := make([]int64, 0, len(want))
+		for k := range want {
+			expected = append(expected, k)
+		}
+		slices.Sort(expected)
+
+		if !slices.Equal(keys, expected) {
+			t.Fatalf("seed %d: in-order keys %v, want %v", seed, keys, expected)
+		}
+
+		var asc, desc []int64
+		tr.walkAsc(func(l *PriceLevel) { asc = append(asc, l.Price) })
+		tr.walkDesc(func(l *PriceLevel) { desc = append(desc, l.Price) })
+		slices.Reverse(desc)
+		if !slices.Equal(asc, expected) || !slices.Equal(desc, expected) {
+			t.Fatalf("seed %d: walkers disagree with in-order keys", seed)
+		}
+
+		if len(expected) > 0 {
+			if tr.BestMin().Price != expected[0] || tr.BestMax().Price != expected[len(expected)-1] {
+				t.Fatalf("seed %d: wrong best prices", seed)
+			}
+		}
+	}
+}
+
+func TestRBTreeDeleteAll(t *testing.T) {
+	tr := NewRBTree()
+	for p := int64(0); p < 1000; p++ {
+		tr.GetOrCreate(p)
+	}
+	for p := int64(999); p >= 0; p -= 2 {
+		tr.Delete(p)
+		checkInvariants(t, tr)
+	}
+	for p := int64(0); p < 1000; p += 2 {
+		tr.Delete(p)
+	}
+	if tr.root != tr.nil || tr.BestMin() != nil || tr.BestMax() != nil {
+		t.Fatal("tree not empty after deleting every key")
+	}
+}
+
+func TestRBTreeDepth(t *testing.T) {
+	tr := NewRBTree()
+	for _, p := range []int64{100, 101, 102, 103} {
+		tr.GetOrCreate(p).TotalQty = 10
+	}
+
+	cases := []struct {
+		name        string
+		depth       func(limit, want int64) int64
+		limit, want int64
+		got         int64
+	}{
+		{"asc within limit", tr.DepthAsc, 101, 100, 20},
+		{"asc stops once enough", tr.DepthAsc, 103, 15, 20},
+		{"asc below best", tr.DepthAsc, 99, 5, 0},
+		{"desc within limit", tr.DepthDesc, 102, 100, 20},
+		{"desc stops once enough", tr.DepthDesc, 100, 25, 30},
+		{"desc above best", tr.DepthDesc, 104, 5, 0},
+	}
+	for _, c := range cases {
+		if got := c.depth(c.limit, c.want); got != c.got {
+			t.Errorf("%s: depth(%d, %d) = %d, want %d", c.name, c.limit, c.want, got, c.got)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package orderbook
+
+// filledMemory is how many fully filled order IDs a book keeps
+// around so Cancel and Amend can tell them from unknown IDs.
+const filledMemory = 8192
+
+// recentIDs is a fixed-size set that forgets its oldest ID once
+// full. ID 0 is never stored.
+type recentIDs struct {
+	set  map[uint64]struct{}
+	ring []uint64
+	next int
+}
+
+func newRecentIDs(n int) *recentIDs {
+	return &recentIDs{
+		set:  make(map[uint64]struct{}, n),
+		ring: make([]uint64, n),
+	}
+}
+
+func (r *recentIDs) add(id uint64) {
+	if old := r.ring[r.next]; old != 0 {
+		delete(r.set, old)
+	}
+	r.ring[r.next] = id
+	r.set[id] = struct{}{}
+	r.next = (r.next + 1) % len(r.ring)
+}
+
+func (r *recentIDs) has(id uint64) bool {
+	_, ok := r.set[id]
+	return ok
+}
@@ -0,0 +1,86 @@
+package orderbook
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Registry maps instrument symbols to their books.
+//
+// Books themselves stay single-writer; the registry lock only
+// guards the symbol table so instruments can be added at runtime.
+type Registry struct {
+	mu    sync.RWMutex
+	books map[string]*OrderBook
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		books: make(map[string]*OrderBook),
+	}
+}
+
+// Create adds a new, empty book for inst.Symbol.
+func (r *Registry) Create(inst Instrument) (*OrderBook, error) {
+	if err := inst.Validate(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.books[inst.Symbol]; ok {
+		return nil, ErrInstrumentExists
+	}
+
+	b := NewOrderBook()
+	b.Symbol = inst.Symbol
+	b.TickSize = inst.TickSize
+	b.PostOnly = inst.PostOnly
+	b.Rules = inst.Rules
+
+	r.books[inst.Symbol] = b
+	return b, nil
+}
+
+// Get returns the book for symbol, or nil.
+func (r *Registry) Get(symbol string) *OrderBook {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.books[symbol]
+}
+
+// Instruments lists every registered instrument sorted by symbol.
+func (r *Registry) Instruments() []Instrument {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]Instrument, 0, len(r.books))
+	for _, b := range r.books {
+		out = append(out, b.Instrument())
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Symbol < out[j].Symbol
+	})
+	return out
+}
+
+// Walk visits every book in symbol order.
+func (r *Registry) Walk(fn func(*OrderBook)) {
+	for _, inst := range r.Instruments() {
+		if b := r.Get(inst.Symbol); b != nil {
+			fn(b)
+		}
+	}
+}
+
+// MaxSymbolLen is the longest symbol the entry WAL can journal.
+const MaxSymbolLen = 255
+
+// ValidSymbol reports whether symbol is non-empty, at most
+// MaxSymbolLen bytes and free of whitespace and '|'.
+func ValidSymbol(symbol string) bool {
+	return symbol != "" && len(symbol) <= MaxSymbolLen &&
+		!strings.ContainsAny(symbol, "| \t\r\n")
+}
@@ -0,0 +1,99 @@
+package orderbook
+
+import (
+	"errors"
+	"math/bits"
+)
+
+// validationErrs are the rule failures from check. They all
+// reject the order before it touches the book.
+var validationErrs = []error{
+	ErrInvalidSide,
+	ErrInvalidType,
+	ErrInvalidQty,
+	ErrInvalidPrice,
+	ErrTickSize,
+	ErrLotSize,
+	ErrQtyOutOfRange,
+	ErrMinNotional,
+	ErrPriceBand,
+}
+
+// IsRuleViolation reports whether err is an order that failed
+// the instrument's rules, as opposed to a book-state rejection
+// such as ErrFOKNotFillable.
+func IsRuleViolation(err error) bool {
+	for _, v := range validationErrs {
+		if errors.Is(err, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// check validates an order's fields against the book's
+// instrument rules. It reads only the rules and LastPrice, so it
+// gives the same answer on replay.
+func (b *OrderBook) check(side Side, typ OrderType, price, qty int64) error {
+	if side != Bid && side != Ask {
+		return ErrInvalidSide
+	}
+	if typ < Limit || typ > PostOnly {
+		return ErrInvalidType
+	}
+	if qty <= 0 {
+		return ErrInvalidQty
+	}
+
+	// ---- price ----
+	if typ == Market {
+		if price != 0 {
+			return ErrInvalidPrice
+		}
+	} else {
+		if price <= 0 {
+			return ErrInvalidPrice
+		}
+		if b.TickSize > 0 && price%b.TickSize != 0 {
+			return ErrTickSize
+		}
+	}
+
+	// ---- quantity ----
+	r := b.Rules
+	if r.LotSize > 0 && qty%r.LotSize != 0 {
+		return ErrLotSize
+	}
+	if qty < r.MinQty || (r.MaxQty > 0 && qty > r.MaxQty) {
+		return ErrQtyOutOfRange
+	}
+
+	if typ == Market {
+		return nil
+	}
+
+	// ---- notional and band ----
+	if r.MinNotional > 0 {
+		hi, lo := bits.Mul64(uint64(price), uint64(qty))
+		if hi == 0 && lo < uint64(r.MinNotional) {
+			return ErrMinNotional
+		}
+	}
+	if r.PriceBandBps > 0 && b.LastPrice > 0 && !inBand(price, b.LastPrice, r.PriceBandBps) {
+		return ErrPriceBand
+	}
+	return nil
+}
+
+// inBand reports whether price is within bps basis points of
+// ref. Both sides are compared as 128-bit products so extreme
+// prices cannot overflow.
+func inBand(price, ref, bps int64) bool {
+	diff := price - ref
+	if diff < 0 {
+		diff = -diff
+	}
+	hi1, lo1 := bits.Mul64(uint64(diff), 10_000)
+	hi2, lo2 := bits.Mul64(uint64(ref), uint64(bps))
+	return hi1 < hi2 || (hi1 == hi2 && lo1 <= lo2)
+}
@@ -0,0 +1,12 @@
+package orderbook
+
+// Trade is a single execution between a resting maker
+// and the incoming taker. It always prints at the maker's price.
+type Trade struct {
+	Seq       uint64
+	MakerID   uint64
+	TakerID   uint64
+	Price     int64
+	Qty       int64
+	Aggressor Side
+}
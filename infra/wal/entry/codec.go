@@ -0,0 +1,269 @@
+package entry
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+Command payload codec.
+
+Every payload starts with a schema version byte, followed by a
+fixed big-endian layout per record type. Symbols are the only
+variable field and are length-prefixed (max 255 bytes).
+
+	place      v1: [ver][symLen][sym][userID:8][side:1][type:1][price:8][qty:8]
+	cancel     v1: [ver][symLen][sym][orderID:8]
+	amend      v1: [ver][symLen][sym][orderID:8][price:8][qty:8]
+	instrument v1: [ver][symLen][sym][tick:8][postOnly:1]
+	instrument v2: v1 + [lot:8][minQty:8][maxQty:8][minNotional:8][bandBps:8]
+
+Versions are < 0x20, so they never collide with the legacy
+pipe-delimited place records (userID|side|type|price|qty) the
+first release wrote. Those are still decoded, with an empty
+Symbol.
+*/
+
+const (
+	PlaceV1      byte = 1
+	CancelV1     byte = 1
+	AmendV1      byte = 1
+	InstrumentV1 byte = 1
+	InstrumentV2 byte = 2
+)
+
+var (
+	ErrShortPayload   = errors.New("entry: short payload")
+	ErrUnknownVersion = errors.New("entry: unknown payload version")
+	ErrSymbolTooLong  = errors.New("entry: symbol longer than 255 bytes")
+)
+
+type PlaceCommand struct {
+	Symbol string
+	UserID uint64
+	Side   uint8
+	Type   uint8
+	Price  int64
+	Qty    int64
+
+	// Legacy is set for text records from the first release,
+	// written before orders were checked against any rules.
+	Legacy bool
+}
+
+type CancelCommand struct {
+	Symbol  string
+	OrderID uint64
+}
+
+type AmendCommand struct {
+	Symbol  string
+	OrderID uint64
+	Price   int64
+	Qty     int64
+}
+
+type InstrumentCommand struct {
+	Symbol   string
+	TickSize int64
+	PostOnly uint8
+
+	// Order rules, v2 onwards. v1 records decode with all zero.
+	LotSize      int64
+	MinQty       int64
+	MaxQty       int64
+	MinNotional  int64
+	PriceBandBps int64
+}
+
+// -------------------- ENCODE --------------------
+
+func EncodePlace(c *PlaceCommand) ([]byte, error) {
+	b, err := putHeader(PlaceV1, c.Symbol, 8+1+1+8+8)
+	if err != nil {
+		return nil, err
+	}
+	b = binary.BigEndian.AppendUint64(b, c.UserID)
+	b = append(b, c.Side, c.Type)
+	b = binary.BigEndian.AppendUint64(b, uint64(c.Price))
+	b = binary.BigEndian.AppendUint64(b, uint64(c.Qty))
+	return b, nil
+}
+
+func EncodeCancel(c *CancelCommand) ([]byte, error) {
+	b, err := putHeader(CancelV1, c.Symbol, 8)
+	if err != nil {
+		return nil, err
+	}
+	return binary.BigEndian.AppendUint64(b, c.OrderID), nil
+}
+
+func EncodeAmend(c *AmendCommand) ([]byte, error) {
+	b, err := putHeader(AmendV1, c.Symbol, 8+8+8)
+	if err != nil {
+		return nil, err
+	}
+	b = binary.BigEndian.AppendUint64(b, c.OrderID)
+	b = binary.BigEndian.AppendUint64(b, uint64(c.Price))
+	b = binary.BigEndian.AppendUint64(b, uint64(c.Qty))
+	return b, nil
+}
+
+func EncodeInstrument(c *InstrumentCommand) ([]byte, error) {
+	b, err := putHeader(InstrumentV2, c.Symbol, 8+1+5*8)
+	if err != nil {
+		return nil, err
+	}
+	b = binary.BigEndian.AppendUint64(b, uint64(c.TickSize))
+	b = append(b, c.PostOnly)
+	for _, v := range []int64{c.LotSize, c.MinQty, c.MaxQty, c.MinNotional, c.PriceBandBps} {
+		b = binary.BigEndian.AppendUint64(b, uint64(v))
+	}
+	return b, nil
+}
+
+func putHeader(ver byte, symbol string, rest int) ([]byte, error) {
+	if len(symbol) > 255 {
+		return nil, ErrSymbolTooLong
+	}
+	b := make([]byte, 0, 2+len(symbol)+rest)
+	b = append(b, ver, byte(len(symbol)))
+	return append(b, symbol...), nil
+}
+
+// -------------------- DECODE --------------------
+
+func DecodePlace(data []byte) (PlaceCommand, error) {
+	var c PlaceCommand
+	if isLegacy(data) {
+		return decodeLegacyPlace(data)
+	}
+
+	d, err := newDecoder(data, PlaceV1)
+	if err != nil {
+		return c, err
+	}
+	c.Symbol = d.symbol()
+	c.UserID = d.u64()
+	c.Side = d.u8()
+	c.Type = d.u8()
+	c.Price = int64(d.u64())
+	c.Qty = int64(d.u64())
+	return c, d.err
+}
+
+func DecodeCancel(data []byte) (CancelCommand, error) {
+	var c CancelCommand
+	d, err := newDecoder(data, CancelV1)
+	if err != nil {
+		return c, err
+	}
+	c.Symbol = d.symbol()
+	c.OrderID = d.u64()
+	return c, d.err
+}
+
+func DecodeAmend(data []byte) (AmendCommand, error) {
+	var c AmendCommand
+	d, err := newDecoder(data, AmendV1)
+	if err != nil {
+		return c, err
+	}
+	c.Symbol = d.symbol()
+	c.OrderID = d.u64()
+	c.Price = int64(d.u64())
+	c.Qty = int64(d.u64())
+	return c, d.err
+}
+
+func DecodeInstrument(data []byte) (InstrumentCommand, error) {
+	var c InstrumentCommand
+	d, err := newDecoder(data, InstrumentV2)
+	if err != nil {
+		return c, err
+	}
+	c.Symbol = d.symbol()
+	c.TickSize = int64(d.u64())
+	c.PostOnly = d.u8()
+	if data[0] >= InstrumentV2 {
+		c.LotSize = int64(d.u64())
+		c.MinQty = int64(d.u64())
+		c.MaxQty = int64(d.u64())
+		c.MinNotional = int64(d.u64())
+		c.PriceBandBps = int64(d.u64())
+	}
+	return c, d.err
+}
+
+// decoder reads fields in order and remembers the first error,
+// so callers check once at the end.
+type decoder struct {
+	b   []byte
+	err error
+}
+
+func newDecoder(data []byte, maxVer byte) (*decoder, error) {
+	if len(data) == 0 {
+		return nil, ErrShortPayload
+	}
+	if data[0] == 0 || data[0] > maxVer {
+		return nil, fmt.Errorf("%w %d", ErrUnknownVersion, data[0])
+	}
+	return &decoder{b: data[1:]}, nil
+}
+
+func (d *decoder) take(n int) []byte {
+	if d.err != nil || len(d.b) < n {
+		d.err = ErrShortPayload
+		return make([]byte, n)
+	}
+	v := d.b[:n]
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *decoder) u8() uint8      { return d.take(1)[0] }
+func (d *decoder) u64() uint64    { return binary.BigEndian.Uint64(d.take(8)) }
+func (d *decoder) symbol() string { return string(d.take(int(d.u8()))) }
+
+// -------------------- LEGACY (pipe-delimited) --------------------
+
+func isLegacy(data []byte) bool {
+	return len(data) > 0 && data[0] >= 0x20
+}
+
+func invalidLegacy(data []byte) error {
+	return fmt.Errorf("invalid WAL payload: %s", string(data))
+}
+
+func decodeLegacyPlace(data []byte) (PlaceCommand, error) {
+	// userID|side|type|price|qty
+	parts := strings.Split(string(data), "|")
+	if len(parts) != 5 {
+		return PlaceCommand{}, invalidLegacy(data)
+	}
+
+	userID, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return PlaceCommand{}, err
+	}
+
+	v := make([]int64, 4)
+	for i, p := range parts[1:] {
+		if v[i], err = strconv.ParseInt(p, 10, 64); err != nil {
+			return PlaceCommand{}, err
+		}
+	}
+
+	return PlaceCommand{
+		UserID: userID,
+		Side:   uint8(v[0]),
+		Type:   uint8(v[1]),
+		Price:  v[2],
+		Qty:    v[3],
+		Legacy: true,
+	}, nil
+}
@@ -0,0 +1,61 @@
+package entry
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	place := PlaceCommand{Symbol: "BTC-USD", UserID: 7, Side: 1, Type: 4, Price: -3, Qty: 1 << 40}
+	b, err := EncodePlace(&place)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := DecodePlace(b); err != nil || got != place {
+		t.Fatalf("place: got %+v, %v", got, err)
+	}
+
+	cancel := CancelCommand{Symbol: "ETH-USD", OrderID: 42}
+	b, _ = EncodeCancel(&cancel)
+	if got, err := DecodeCancel(b); err != nil || got != cancel {
+		t.Fatalf("cancel: got %+v, %v", got, err)
+	}
+
+	amend := AmendCommand{Symbol: "X", OrderID: 9, Price: 101, Qty: 3}
+	b, _ = EncodeAmend(&amend)
+	if got, err := DecodeAmend(b); err != nil || got != amend {
+		t.Fatalf("amend: got %+v, %v", got, err)
+	}
+
+	inst := InstrumentCommand{
+		Symbol: "SOL-USD", TickSize: 5, PostOnly: 1,
+		LotSize: 10, MinQty: 10, MaxQty: 1000, MinNotional: 500, PriceBandBps: 250,
+	}
+	b, _ = EncodeInstrument(&inst)
+	if got, err := DecodeInstrument(b); err != nil || got != inst {
+		t.Fatalf("instrument: got %+v, %v", got, err)
+	}
+
+	// v1 instrument records predate rules.
+	v1 := append([]byte{InstrumentV1, 3}, "BTC"...)
+	v1 = binary.BigEndian.AppendUint64(v1, 5)
+	v1 = append(v1, 1)
+	if got, err := DecodeInstrument(v1); err != nil || got != (InstrumentCommand{Symbol: "BTC", TickSize: 5, PostOnly: 1}) {
+		t.Fatalf("instrument v1: got %+v, %v", got, err)
+	}
+
+	if _, err := DecodeInstrument(b[:len(b)-1]); !errors.Is(err, ErrShortPayload) {
+		t.Fatalf("truncated payload: err=%v", err)
+	}
+	if _, err := DecodeCancel([]byte{9, 0}); !errors.Is(err, ErrUnknownVersion) {
+		t.Fatalf("future version: err=%v", err)
+	}
+}
+
+func TestCodecLegacyPayloads(t *testing.T) {
+	p, err := DecodePlace([]byte("1|0|0|100|5"))
+	if err != nil || p != (PlaceCommand{UserID: 1, Price: 100, Qty: 5, Legacy: true}) {
+		t.Fatalf("legacy place: %+v, %v", p, err)
+	}
+}
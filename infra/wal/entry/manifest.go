@@ -0,0 +1,141 @@
+package entry
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const manifestName = "MANIFEST"
+
+// segmentRange is the seq span of one segment. FirstSeq is 0
+// while the segment is empty.
+type segmentRange struct {
+	Index    int    `json:"index"`
+	FirstSeq uint64 `json:"first_seq"`
+	LastSeq  uint64 `json:"last_seq"`
+}
+
+// manifest lists the seq range of every sealed segment, so
+// truncation and replay can pick segments without reading them.
+// The live segment is never in it.
+type manifest struct {
+	Segments []segmentRange `json:"segments"`
+
+	// Truncated is the highest seq ever deleted by truncation.
+	// It keeps the log's high-water mark when every segment
+	// holding records has been deleted.
+	Truncated uint64 `json:"truncated_through,omitempty"`
+}
+
+func loadManifest(dir string) (*manifest, error) {
+	m := &manifest{}
+
+	b, err := os.ReadFile(filepath.Join(dir, manifestName))
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// save replaces the manifest atomically.
+func (m *manifest) save(dir string) error {
+	sort.Slice(m.Segments, func(i, j int) bool {
+		return m.Segments[i].Index < m.Segments[j].Index
+	})
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(dir, manifestName+".tmp")
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, manifestName)); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// syncDir makes a rename or unlink in dir durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+func (m *manifest) find(index int) (segmentRange, bool) {
+	for _, s := range m.Segments {
+		if s.Index == index {
+			return s, true
+		}
+	}
+	return segmentRange{}, false
+}
+
+// reconcile makes the manifest match the sealed segments on
+// disk: entries for deleted files are dropped and segments it
+// never heard of (a crash mid-rotation, or a log written before
+// the manifest existed) are scanned and added.
+func (m *manifest) reconcile(dir string, sealed []int) (changed bool, err error) {
+	onDisk := make(map[int]bool, len(sealed))
+	for _, idx := range sealed {
+		onDisk[idx] = true
+	}
+
+	kept := m.Segments[:0]
+	for _, s := range m.Segments {
+		if onDisk[s.Index] {
+			kept = append(kept, s)
+		} else {
+			changed = true
+		}
+	}
+	m.Segments = kept
+
+	for _, idx := range sealed {
+		if _, ok := m.find(idx); ok {
+			continue
+		}
+		path := segmentPath(dir, idx)
+		info, err := scanSegment(path, nil)
+		if err != nil {
+			return changed, err
+		}
+		if info.Torn {
+			return changed, info.corruption(path)
+		}
+		m.Segments = append(m.Segments, segmentRange{
+			Index:    idx,
+			FirstSeq: info.FirstSeq,
+			LastSeq:  info.LastSeq,
+		})
+		changed = true
+	}
+	return changed, nil
+}
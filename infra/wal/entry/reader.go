@@ -0,0 +1,248 @@
+package entry
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Reader streams records in seq order starting at a given seq,
+// moving across segments and following the live one as the
+// WAL appends to it. It is safe to use alongside a writer in
+// this or another process, but not from several goroutines.
+type Reader struct {
+	dir  string
+	from uint64
+
+	index int // segment being read
+	f     *os.File
+	br    *bufio.Reader
+	off   int64 // offset of the next frame in f
+
+	// sealed is set once a later segment exists, so whatever is
+	// left in this one is final.
+	sealed bool
+
+	lastSeq uint64
+	// tail is the number of bytes past off at the last io.EOF:
+	// a frame still being written, or torn by a crash.
+	tail int64
+}
+
+// NewReader positions a reader at the first record with seq >=
+// from. Sealed segments that end before from are skipped by
+// their manifest range without being opened.
+func NewReader(dir string, from uint64) (*Reader, error) {
+	m, err := loadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := listSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Reader{dir: dir, from: from, lastSeq: m.Truncated}
+
+	for i, n := range idx {
+		r.index = n
+		if i == len(idx)-1 {
+			break
+		}
+		s, ok := m.find(n)
+		if !ok || s.LastSeq >= from {
+			break
+		}
+		if s.LastSeq > r.lastSeq {
+			r.lastSeq = s.LastSeq
+		}
+	}
+
+	return r, nil
+}
+
+// Next returns the next record. It returns io.EOF once it has
+// caught up with everything written so far; calling it again
+// later picks up new appends.
+func (r *Reader) Next() (*Record, error) {
+	for {
+		if r.f == nil {
+			ok, err := r.open()
+			if err != nil || !ok {
+				if err == nil {
+					err = io.EOF
+				}
+				return nil, err
+			}
+		}
+
+		rec, err := readRecord(r.br)
+		if err == nil {
+			r.off += frameSize(len(rec.Data))
+
+			if rec.Seq <= r.lastSeq {
+				return nil, fmt.Errorf("non-monotonic seq %d", rec.Seq)
+			}
+			r.lastSeq = rec.Seq
+			r.tail = 0
+
+			if rec.Seq < r.from {
+				continue
+			}
+			return rec, nil
+		}
+
+		torn := err == io.EOF || err == io.ErrUnexpectedEOF
+		if !torn && !errors.Is(err, ErrChecksum) {
+			return nil, err
+		}
+
+		st, serr := r.f.Stat()
+		if serr != nil {
+			return nil, serr
+		}
+		size := st.Size()
+
+		// a bad frame is a torn tail unless an intact, later
+		// frame follows it
+		if err != io.EOF {
+			mid, ierr := intactFrameAfter(r.f, r.off, r.lastSeq)
+			if ierr != nil {
+				return nil, ierr
+			}
+			if mid {
+				return nil, r.corruption(err)
+			}
+		}
+
+		if !r.sealed {
+			next, err := r.hasSuccessor()
+			if err != nil {
+				return nil, err
+			}
+			if !next {
+				r.tail = size - r.off
+				if err := r.rewind(); err != nil {
+					return nil, err
+				}
+				return nil, io.EOF
+			}
+			// rotation may have landed after our read: go over
+			// the rest of this segment once more before leaving
+			r.sealed = true
+			if err := r.rewind(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if size > r.off {
+			return nil, r.corruption(err)
+		}
+
+		_ = r.f.Close()
+		r.f, r.br = nil, nil
+		r.index++
+		r.off, r.sealed = 0, false
+	}
+}
+
+// Follow hands every record to fn as it lands, polling the WAL
+// every poll once caught up, until ctx is done or fn fails.
+func (r *Reader) Follow(ctx context.Context, poll time.Duration, fn ReplayHandler) error {
+	t := time.NewTicker(poll)
+	defer t.Stop()
+
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-t.C:
+				continue
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+}
+
+// LastSeq is the highest seq seen so far, including records and
+// segments skipped because they were below from, and records
+// already deleted by truncation.
+func (r *Reader) LastSeq() uint64 {
+	return r.lastSeq
+}
+
+func (r *Reader) Close() error {
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f, r.br = nil, nil
+	return err
+}
+
+// open opens the first segment at or after r.index. Segments
+// can vanish under truncation, so gaps in the numbering are
+// skipped. It reports false if there is nothing to open yet.
+func (r *Reader) open() (bool, error) {
+	idx, err := listSegments(r.dir)
+	if err != nil {
+		return false, err
+	}
+
+	for _, n := range idx {
+		if n < r.index {
+			continue
+		}
+		f, err := os.Open(segmentPath(r.dir, n))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		r.index = n
+		r.f, r.br = f, bufio.NewReader(f)
+		r.off, r.sealed = 0, false
+		return true, nil
+	}
+	return false, nil
+}
+
+func (r *Reader) hasSuccessor() (bool, error) {
+	idx, err := listSegments(r.dir)
+	if err != nil {
+		return false, err
+	}
+	return len(idx) > 0 && idx[len(idx)-1] > r.index, nil
+}
+
+// rewind drops anything buffered past r.off so the next read
+// sees bytes appended since.
+func (r *Reader) rewind() error {
+	if _, err := r.f.Seek(r.off, io.SeekStart); err != nil {
+		return err
+	}
+	r.br.Reset(r.f)
+	return nil
+}
+
+func (r *Reader) corruption(err error) *CorruptionError {
+	return &CorruptionError{
+		Segment: segmentPath(r.dir, r.index),
+		Offset:  r.off,
+		Seq:     r.lastSeq,
+		Err:     err,
+	}
+}
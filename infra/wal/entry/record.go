@@ -1,12 +1,23 @@
 package entry
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// maxPayload bounds a record's payload. Commands are a few
+// hundred bytes; anything near the limit is a damaged header.
+const maxPayload = 64 << 10
+
+var ErrRecordTooLarge = errors.New("entry: record payload too large")
 
 type RecordType uint8
 
 const (
 	RecordPlace RecordType = iota
 	RecordCancel
+	RecordInstrument
+	RecordAmend
 )
 
 type Record struct {
@@ -2,50 +2,74 @@ package entry
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
-	"os"
-	"path/filepath"
+	"log"
 )
 
 type ReplayHandler func(*Record) error
 
+var ErrChecksum = errors.New("entry: crc mismatch")
+
+// CorruptionError reports a frame that failed validation where
+// a torn write cannot explain it: anywhere but the tail of the
+// last segment. Nothing past it has been replayed.
+type CorruptionError struct {
+	Segment string
+	Offset  int64
+	// Seq is the last intact seq before Offset, 0 if none.
+	Seq uint64
+	Err error
+}
+
+func (e *CorruptionError) Error() string {
+	return fmt.Sprintf("entry: corrupt WAL %s at offset %d after seq %d: %v",
+		e.Segment, e.Offset, e.Seq, e.Err)
+}
+
+func (e *CorruptionError) Unwrap() error { return e.Err }
+
+// Replay feeds every record to fn in order. A torn tail on the
+// last segment is a normal crash artifact: it is skipped with a
+// warning. Any other bad frame is a *CorruptionError.
 func Replay(dir string, fn ReplayHandler) (lastSeq uint64, err error) {
-	files, err := filepath.Glob(filepath.Join(dir, "segment-*.wal"))
+	return ReplayFrom(dir, 0, fn)
+}
+
+// ReplayFrom is Replay restricted to records with seq >= from.
+// Sealed segments that end before from are skipped by their
+// manifest range without being read. lastSeq is the highest seq
+// in the log, whether or not it was handed to fn or still on
+// disk.
+func ReplayFrom(dir string, from uint64, fn ReplayHandler) (lastSeq uint64, err error) {
+	r, err := NewReader(dir, from)
 	if err != nil {
 		return 0, err
 	}
+	defer r.Close()
 
-	for _, path := range files {
-		f, err := os.Open(path)
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			if r.tail > 0 {
+				log.Printf("entry WAL: ignoring torn tail of %s at offset %d (%d bytes) after seq %d",
+					segmentPath(dir, r.index), r.off, r.tail, r.lastSeq)
+			}
+			return r.lastSeq, nil
+		}
 		if err != nil {
-			return lastSeq, err
+			return r.lastSeq, err
 		}
-
-		for {
-			rec, err := readRecord(f)
-			if err != nil {
-				if err == io.EOF {
-					break
-				}
-				return lastSeq, err
-			}
-
-			if rec.Seq <= lastSeq {
-				return lastSeq, fmt.Errorf("non-monotonic seq %d", rec.Seq)
-			}
-			lastSeq = rec.Seq
-
-			if err := fn(rec); err != nil {
-				return lastSeq, err
-			}
+		if err := fn(rec); err != nil {
+			return r.lastSeq, err
 		}
-		_ = f.Close()
 	}
-
-	return lastSeq, nil
 }
 
+// readRecord reads one frame. On ErrChecksum the record is
+// returned as well, so the caller knows how long the frame was,
+// except when the length itself is out of range.
 func readRecord(r io.Reader) (*Record, error) {
 	header := make([]byte, 21)
 	if _, err := io.ReadFull(r, header); err != nil {
@@ -57,22 +81,31 @@ func readRecord(r io.Reader) (*Record, error) {
 	ts := binary.BigEndian.Uint64(header[9:17])
 	l := binary.BigEndian.Uint32(header[17:21])
 
+	// never trust a length no writer could have produced
+	if l > maxPayload {
+		return nil, ErrChecksum
+	}
+
 	data := make([]byte, l+4)
 	if _, err := io.ReadFull(r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
 		return nil, err
 	}
 
 	payload := data[:l]
 	crc := binary.BigEndian.Uint32(data[l:])
 
-	if !CRC32Valid(append(header, payload...), crc) {
-		return nil, fmt.Errorf("crc mismatch")
-	}
-
-	return &Record{
+	rec := &Record{
 		Type: t,
 		Seq:  seq,
 		Time: int64(ts),
 		Data: payload,
-	}, nil
+	}
+
+	if !CRC32Valid(append(header, payload...), crc) {
+		return rec, ErrChecksum
+	}
+	return rec, nil
 }
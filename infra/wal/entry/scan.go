@@ -1,42 +1,136 @@
 package entry
 
 import (
+	"bufio"
 	"encoding/binary"
+	"errors"
 	"io"
 	"os"
 )
 
-// maxSeqInSegment scans a WAL segment and returns the maximum sequence ID found.
-// It is used ONLY for snapshot-based truncation.
-func maxSeqInSegment(path string) (uint64, error) {
+// segmentInfo describes the valid prefix of a segment.
+type segmentInfo struct {
+	FirstSeq uint64
+	LastSeq  uint64
+	Records  int
+
+	// Valid is the offset just past the last intact frame.
+	Valid int64
+	Size  int64
+
+	// Torn is set when the bytes after Valid look like a write
+	// cut short by a crash: a bad or incomplete frame with no
+	// intact, later frame anywhere after it. That covers a batch
+	// written partway and whatever zero-fill or stale blocks the
+	// filesystem left behind it.
+	Torn    bool
+	TornErr error
+}
+
+func (info segmentInfo) corruption(path string) *CorruptionError {
+	return &CorruptionError{
+		Segment: path,
+		Offset:  info.Valid,
+		Seq:     info.LastSeq,
+		Err:     info.TornErr,
+	}
+}
+
+// scanSegment walks a segment frame by frame, checking CRCs and
+// handing each intact record to fn if it is not nil. A bad frame
+// followed by an intact one with a higher seq is returned as a
+// *CorruptionError.
+func scanSegment(path string, fn func(*Record) error) (segmentInfo, error) {
+	var info segmentInfo
+
 	f, err := os.Open(path)
 	if err != nil {
-		return 0, err
+		return info, err
 	}
 	defer f.Close()
 
-	var max uint64
+	st, err := f.Stat()
+	if err != nil {
+		return info, err
+	}
+	info.Size = st.Size()
 
+	r := bufio.NewReader(f)
 	for {
-		// Header: [type:1][seq:8][time:8][len:4]
-		header := make([]byte, 21)
-		if _, err := io.ReadFull(f, header); err != nil {
-			if err == io.EOF || err == io.ErrUnexpectedEOF {
-				return max, nil
+		rec, err := readRecord(r)
+		switch {
+		case err == io.EOF:
+			return info, nil
+
+		case err == io.ErrUnexpectedEOF, errors.Is(err, ErrChecksum):
+			info.TornErr = err
+			mid, err := intactFrameAfter(f, info.Valid, info.LastSeq)
+			if err != nil {
+				return info, err
+			}
+			if mid {
+				return info, info.corruption(path)
+			}
+			info.Torn = true
+			return info, nil
+
+		case err != nil:
+			return info, err
+		}
+
+		if fn != nil {
+			if err := fn(rec); err != nil {
+				return info, err
 			}
-			return max, err
 		}
 
-		seq := binary.BigEndian.Uint64(header[1:9])
-		if seq > max {
-			max = seq
+		if info.Records == 0 {
+			info.FirstSeq = rec.Seq
 		}
+		info.LastSeq = rec.Seq
+		info.Records++
+		info.Valid += frameSize(len(rec.Data))
+	}
+}
+
+func frameSize(payload int) int64 {
+	return int64(1 + 8 + 8 + 4 + payload + 4)
+}
+
+// intactFrameAfter reports whether an intact frame with a seq
+// above after starts anywhere past the bad frame at off. Only
+// that proves records were lost from the middle of the log; a
+// crash leaves nothing valid behind the frame it cut short.
+func intactFrameAfter(f *os.File, off int64, after uint64) (bool, error) {
+	st, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+	if st.Size()-off-1 < frameSize(0) {
+		return false, nil
+	}
 
-		payloadLen := binary.BigEndian.Uint32(header[17:21])
+	b := make([]byte, st.Size()-off-1)
+	if _, err := f.ReadAt(b, off+1); err != nil && err != io.EOF {
+		return false, err
+	}
 
-		// Skip payload + CRC
-		if _, err := f.Seek(int64(payloadLen+4), io.SeekCurrent); err != nil {
-			return max, err
+	for i := int64(0); i+frameSize(0) <= int64(len(b)); i++ {
+		h := b[i:]
+		if binary.BigEndian.Uint64(h[1:9]) <= after {
+			continue
+		}
+		l := binary.BigEndian.Uint32(h[17:21])
+		if l > maxPayload {
+			continue
+		}
+		n := frameSize(int(l))
+		if n > int64(len(h)) {
+			continue
+		}
+		if CRC32Valid(h[:n-4], binary.BigEndian.Uint32(h[n-4:n])) {
+			return true, nil
 		}
 	}
+	return false, nil
 }
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 type segment struct {
@@ -11,13 +14,44 @@ type segment struct {
 	offset int64
 }
 
+func segmentPath(dir string, index int) string {
+	return filepath.Join(dir, fmt.Sprintf("segment-%06d.wal", index))
+}
+
+// openSegment opens or creates a segment for appending.
+// offset starts at the current file size.
 func openSegment(dir string, index int) (*segment, error) {
-	path := filepath.Join(dir, fmt.Sprintf("segment-%06d.wal", index))
-	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	f, err := os.OpenFile(segmentPath(dir, index), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &segment{file: f, offset: st.Size()}, nil
+}
+
+// listSegments returns the indexes of all segments in dir,
+// in ascending order.
+func listSegments(dir string) ([]int, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "segment-*.wal"))
 	if err != nil {
 		return nil, err
 	}
-	return &segment{file: f}, nil
+
+	idx := make([]int, 0, len(files))
+	for _, path := range files {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "segment-"), ".wal")
+		n, err := strconv.Atoi(name)
+		if err != nil {
+			continue
+		}
+		idx = append(idx, n)
+	}
+	sort.Ints(idx)
+	return idx, nil
 }
 
 func (s *segment) append(b []byte) error {
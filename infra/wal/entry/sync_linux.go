@@ -0,0 +1,12 @@
+package entry
+
+import (
+	"os"
+	"syscall"
+)
+
+// fdatasync flushes file data without forcing a metadata-only
+// update of mtime, which is all the WAL needs.
+func fdatasync(f *os.File) error {
+	return syscall.Fdatasync(int(f.Fd()))
+}
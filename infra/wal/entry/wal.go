@@ -2,23 +2,73 @@ package entry
 
 import (
 	"encoding/binary"
+	"errors"
+	"log"
 	"os"
-	"path/filepath"
+	"sync"
 	"time"
 )
 
+// SyncMode decides when appended records reach stable storage.
+type SyncMode int
+
+const (
+	// SyncEveryRecord fdatasyncs before every Append or
+	// AppendBatch returns.
+	SyncEveryRecord SyncMode = iota
+	// SyncGroupCommit batches concurrent appends into a single
+	// write + fdatasync and releases all their callers together.
+	// It only pays off with several appenders: a lone caller that
+	// already uses AppendBatch gets nothing from it.
+	SyncGroupCommit
+	// SyncInterval returns after the write and fdatasyncs in the
+	// background every Config.SyncInterval. A crash can lose
+	// up to one interval of acknowledged records.
+	SyncInterval
+)
+
+var ErrClosed = errors.New("entry: WAL closed")
+
+// datasync is swapped out by tests to observe syncs.
+var datasync = fdatasync
+
 type Config struct {
 	Dir             string
 	SegmentSize     int64
 	SegmentDuration time.Duration
+
+	Durability   SyncMode
+	SyncInterval time.Duration
 }
 
 type WAL struct {
-	dir        string
+	dir  string
+	mode SyncMode
+
+	// mu guards the segment: writes, syncs, rotation and the
+	// manifest.
+	mu         sync.Mutex
 	segSize    int64
+	segDur     time.Duration
 	current    *segment
 	segIndex   int
+	firstSeq   uint64 // of the live segment, 0 while empty
+	lastSeq    uint64
+	appended   uint64 // highest seq in the log
 	lastRotate time.Time
+	dirty      bool
+	manifest   *manifest
+
+	// group commit queue, guarded by gmu
+	gmu         sync.Mutex
+	gcond       *sync.Cond
+	pending     []byte
+	pendingSeqs segmentRange
+	waiters     []chan error
+	closed      bool
+
+	stop chan struct{}
+	wg   sync.WaitGroup
 }
 
 func Open(cfg Config) (*WAL, error) {
@@ -26,25 +76,183 @@ func Open(cfg Config) (*WAL, error) {
 		return nil, err
 	}
 
-	seg, err := openSegment(cfg.Dir, 0)
-	if err != nil {
-		return nil, err
-	}
-
-	return &WAL{
+	w := &WAL{
 		dir:        cfg.Dir,
+		mode:       cfg.Durability,
 		segSize:    cfg.SegmentSize,
-		current:    seg,
+		segDur:     cfg.SegmentDuration,
 		lastRotate: time.Now(),
-	}, nil
+		stop:       make(chan struct{}),
+	}
+	w.gcond = sync.NewCond(&w.gmu)
+
+	if err := w.resume(); err != nil {
+		return nil, err
+	}
+
+	switch cfg.Durability {
+	case SyncGroupCommit:
+		w.wg.Add(1)
+		go w.groupCommitLoop()
+	case SyncInterval:
+		interval := cfg.SyncInterval
+		if interval <= 0 {
+			interval = 10 * time.Millisecond
+		}
+		w.wg.Add(1)
+		go w.intervalSyncLoop(interval)
+	}
+
+	if w.segDur > 0 {
+		w.wg.Add(1)
+		go w.ageLoop()
+	}
+
+	return w, nil
+}
+
+// resume continues the highest existing segment, or a fresh one
+// after it if that is already full. A torn tail left by a crash
+// is cut back to the last intact frame first. Every other
+// segment is sealed and must be in the manifest.
+func (w *WAL) resume() error {
+	m, err := loadManifest(w.dir)
+	if err != nil {
+		return err
+	}
+	w.manifest = m
+
+	idx, err := listSegments(w.dir)
+	if err != nil {
+		return err
+	}
+
+	sealed := idx
+	if len(idx) > 0 {
+		sealed = idx[:len(idx)-1]
+	}
+	changed, err := m.reconcile(w.dir, sealed)
+	if err != nil {
+		return err
+	}
+
+	w.appended = m.Truncated
+	for _, r := range m.Segments {
+		if r.LastSeq > w.appended {
+			w.appended = r.LastSeq
+		}
+	}
+
+	if len(idx) > 0 {
+		w.segIndex = idx[len(idx)-1]
+		path := segmentPath(w.dir, w.segIndex)
+
+		info, err := scanSegment(path, nil)
+		if err != nil {
+			return err
+		}
+		if info.Torn {
+			log.Printf("entry WAL: truncating torn tail of %s at offset %d (%d bytes) after seq %d: %v",
+				path, info.Valid, info.Size-info.Valid, info.LastSeq, info.TornErr)
+			if err := os.Truncate(path, info.Valid); err != nil {
+				return err
+			}
+			info.Size = info.Valid
+		}
+		if info.LastSeq > w.appended {
+			w.appended = info.LastSeq
+		}
+
+		if info.Size >= w.segSize {
+			m.Segments = append(m.Segments, segmentRange{
+				Index:    w.segIndex,
+				FirstSeq: info.FirstSeq,
+				LastSeq:  info.LastSeq,
+			})
+			changed = true
+			w.segIndex++
+		} else {
+			w.firstSeq, w.lastSeq = info.FirstSeq, info.LastSeq
+		}
+	}
+
+	if changed {
+		if err := m.save(w.dir); err != nil {
+			return err
+		}
+	}
+
+	seg, err := openSegment(w.dir, w.segIndex)
+	if err != nil {
+		return err
+	}
+	w.current = seg
+	return nil
 }
 
+// Append durably writes one record according to the sync mode.
 func (w *WAL) Append(r *Record) error {
+	return w.AppendBatch([]*Record{r})
+}
+
+// AppendBatch writes all records with one write and, unless in
+// interval mode, one fdatasync. Records keep their order.
+func (w *WAL) AppendBatch(recs []*Record) error {
+	if len(recs) == 0 {
+		return nil
+	}
+
+	var buf []byte
+	for _, r := range recs {
+		if len(r.Data) > maxPayload {
+			return ErrRecordTooLarge
+		}
+		buf = appendFrame(buf, r)
+	}
+	seqs := segmentRange{FirstSeq: recs[0].Seq, LastSeq: recs[len(recs)-1].Seq}
+
+	switch w.mode {
+	case SyncGroupCommit:
+		return w.enqueue(buf, seqs)
+	case SyncInterval:
+		return w.write(buf, seqs, false)
+	default:
+		return w.write(buf, seqs, true)
+	}
+}
+
+// Close flushes and syncs everything appended so far.
+func (w *WAL) Close() error {
+	w.gmu.Lock()
+	if w.closed {
+		w.gmu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.stop)
+	w.gcond.Broadcast()
+	w.gmu.Unlock()
+
+	w.wg.Wait()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := datasync(w.current.file); err != nil {
+		_ = w.current.close()
+		return err
+	}
+	return w.current.close()
+}
+
+// appendFrame encodes:
+// [type:1][seq:8][time:8][len:4][payload][crc:4]
+func appendFrame(dst []byte, r *Record) []byte {
 	payloadLen := uint32(len(r.Data))
 
-	// Frame:
-	// [type:1][seq:8][time:8][len:4][payload][crc:4]
-	buf := make([]byte, 1+8+8+4+payloadLen+4)
+	start := len(dst)
+	dst = append(dst, make([]byte, 1+8+8+4+payloadLen+4)...)
+	buf := dst[start:]
 
 	buf[0] = byte(r.Type)
 	binary.BigEndian.PutUint64(buf[1:9], r.Seq)
@@ -55,17 +263,163 @@ func (w *WAL) Append(r *Record) error {
 	crc := CRC32(buf[:21+payloadLen])
 	binary.BigEndian.PutUint32(buf[21+payloadLen:], crc)
 
+	return dst
+}
+
+// write appends buf, holding the records in seqs, to the current
+// segment, optionally syncs, and rotates once the segment is full
+// or older than SegmentDuration.
+func (w *WAL) write(buf []byte, seqs segmentRange, sync bool) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
 	if err := w.current.append(buf); err != nil {
 		return err
 	}
+	w.dirty = true
+	if w.firstSeq == 0 {
+		w.firstSeq = seqs.FirstSeq
+	}
+	w.lastSeq = seqs.LastSeq
+	w.appended = seqs.LastSeq
+
+	if sync {
+		if err := datasync(w.current.file); err != nil {
+			return err
+		}
+		w.dirty = false
+	}
 
-	if w.current.offset >= w.segSize {
+	full := w.current.offset >= w.segSize
+	aged := w.segDur > 0 && time.Since(w.lastRotate) >= w.segDur
+	if full || aged {
 		return w.rotate()
 	}
 	return nil
 }
 
+// -------------------- GROUP COMMIT --------------------
+
+func (w *WAL) enqueue(buf []byte, seqs segmentRange) error {
+	done := make(chan error, 1)
+
+	w.gmu.Lock()
+	if w.closed {
+		w.gmu.Unlock()
+		return ErrClosed
+	}
+	w.pending = append(w.pending, buf...)
+	if w.pendingSeqs.FirstSeq == 0 {
+		w.pendingSeqs.FirstSeq = seqs.FirstSeq
+	}
+	w.pendingSeqs.LastSeq = seqs.LastSeq
+	w.waiters = append(w.waiters, done)
+	w.gcond.Signal()
+	w.gmu.Unlock()
+
+	return <-done
+}
+
+// groupCommitLoop is the only writer in group commit mode.
+// Whatever queued while the previous sync was in flight goes
+// out as the next single write + fdatasync.
+func (w *WAL) groupCommitLoop() {
+	defer w.wg.Done()
+
+	var spare []byte
+
+	for {
+		w.gmu.Lock()
+		for len(w.waiters) == 0 && !w.closed {
+			w.gcond.Wait()
+		}
+		if len(w.waiters) == 0 {
+			w.gmu.Unlock()
+			return
+		}
+		buf, seqs, waiters := w.pending, w.pendingSeqs, w.waiters
+		w.pending, w.pendingSeqs, w.waiters = spare[:0], segmentRange{}, nil
+		w.gmu.Unlock()
+
+		err := w.write(buf, seqs, true)
+		for _, done := range waiters {
+			done <- err
+		}
+		spare = buf
+	}
+}
+
+// -------------------- INTERVAL SYNC --------------------
+
+func (w *WAL) intervalSyncLoop(interval time.Duration) {
+	defer w.wg.Done()
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-t.C:
+			w.mu.Lock()
+			if w.dirty && datasync(w.current.file) == nil {
+				w.dirty = false
+			}
+			w.mu.Unlock()
+		}
+	}
+}
+
+// -------------------- AGE ROTATION --------------------
+
+// ageLoop seals the live segment once it is SegmentDuration old
+// even if nothing is written to it any more, so the manifest
+// covers it and truncation can reclaim it. Busy segments are
+// also rotated by write.
+func (w *WAL) ageLoop() {
+	defer w.wg.Done()
+
+	t := time.NewTicker(max(w.segDur/4, time.Millisecond))
+	defer t.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-t.C:
+			w.mu.Lock()
+			if w.firstSeq != 0 && time.Since(w.lastRotate) >= w.segDur {
+				if err := w.rotate(); err != nil {
+					log.Printf("entry WAL: age rotation of segment %d failed: %v", w.segIndex, err)
+				}
+			}
+			w.mu.Unlock()
+		}
+	}
+}
+
+// -------------------- SEGMENTS --------------------
+
+// rotate seals the current segment and records its seq range
+// in the manifest. It is synced first so no acknowledged record
+// is left only in the page cache of a file we no longer track.
+// Caller holds w.mu.
 func (w *WAL) rotate() error {
+	if err := datasync(w.current.file); err != nil {
+		return err
+	}
+	w.dirty = false
+
+	w.manifest.Segments = append(w.manifest.Segments, segmentRange{
+		Index:    w.segIndex,
+		FirstSeq: w.firstSeq,
+		LastSeq:  w.lastSeq,
+	})
+	if err := w.manifest.save(w.dir); err != nil {
+		return err
+	}
+
 	_ = w.current.close()
 	w.segIndex++
 
@@ -75,24 +429,72 @@ func (w *WAL) rotate() error {
 	}
 
 	w.current = seg
+	w.firstSeq, w.lastSeq = 0, 0
 	w.lastRotate = time.Now()
 	return nil
 }
 
-func (w *WAL) TruncateBefore(seq uint64) error {
-	files, err := filepath.Glob(filepath.Join(w.dir, "segment-*.wal"))
+// LastSeq is the highest seq written to the log, durable or
+// not depending on the sync mode.
+func (w *WAL) LastSeq() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.appended
+}
+
+// TruncateBefore deletes sealed segments whose records are all
+// at or below seq, going by the manifest, and returns the bytes
+// reclaimed. The live segment is never deleted. The highest seq
+// deleted is kept in the manifest, so LastSeq never goes back.
+func (w *WAL) TruncateBefore(seq uint64) (int64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var reclaimed int64
+	kept := w.manifest.Segments[:0]
+	for _, s := range w.manifest.Segments {
+		if s.LastSeq > seq {
+			kept = append(kept, s)
+			continue
+		}
+		path := segmentPath(w.dir, s.Index)
+		var size int64
+		if st, err := os.Stat(path); err == nil {
+			size = st.Size()
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			kept = append(kept, s)
+			continue
+		}
+		reclaimed += size
+		if s.LastSeq > w.manifest.Truncated {
+			w.manifest.Truncated = s.LastSeq
+		}
+	}
+
+	removed := len(kept) != len(w.manifest.Segments)
+	w.manifest.Segments = kept
+
+	if !removed {
+		return 0, nil
+	}
+	return reclaimed, w.manifest.save(w.dir)
+}
+
+// RetainedBytes is the on-disk size of every segment still held.
+func (w *WAL) RetainedBytes() (int64, error) {
+	idx, err := listSegments(w.dir)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	for _, path := range files {
-		maxSeq, err := maxSeqInSegment(path)
+	var n int64
+	for _, i := range idx {
+		st, err := os.Stat(segmentPath(w.dir, i))
 		if err != nil {
 			continue
 		}
-		if maxSeq <= seq {
-			_ = os.Remove(path)
-		}
+		n += st.Size()
 	}
-	return nil
+	return n, nil
 }
@@ -0,0 +1,557 @@
+package entry
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func openTestWAL(t *testing.T, dir string, segSize int64) *WAL {
+	t.Helper()
+	w, err := Open(Config{Dir: dir, SegmentSize: segSize})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func appendSeqs(t *testing.T, w *WAL, from, to uint64) {
+	t.Helper()
+	for seq := from; seq <= to; seq++ {
+		if err := w.Append(NewRecord(RecordPlace, seq, []byte("payload"))); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// replaySeqs replays dir and fails unless it holds exactly 1..want.
+func replaySeqs(t *testing.T, dir string, want uint64) {
+	t.Helper()
+	var next uint64 = 1
+	last, err := Replay(dir, func(r *Record) error {
+		if r.Seq != next {
+			t.Fatalf("replay: got seq %d, want %d", r.Seq, next)
+		}
+		next++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last != want {
+		t.Fatalf("replay: last seq %d, want %d", last, want)
+	}
+}
+
+func TestReopenResumesHighestSegment(t *testing.T) {
+	dir := t.TempDir()
+
+	// ~40 byte frames: rotate every few records
+	w := openTestWAL(t, dir, 100)
+	appendSeqs(t, w, 1, 10)
+	_ = w.Close()
+
+	before, _ := listSegments(dir)
+
+	w = openTestWAL(t, dir, 100)
+	if w.segIndex < before[len(before)-1] {
+		t.Fatalf("reopened at segment %d, highest is %d", w.segIndex, before[len(before)-1])
+	}
+	appendSeqs(t, w, 11, 20)
+	_ = w.Close()
+
+	replaySeqs(t, dir, 20)
+}
+
+func TestReopenAfterTruncate(t *testing.T) {
+	dir := t.TempDir()
+
+	w := openTestWAL(t, dir, 100)
+	appendSeqs(t, w, 1, 10)
+	if _, err := w.TruncateBefore(5); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(segmentPath(dir, 0)); !os.IsNotExist(err) {
+		t.Fatalf("segment 0 should be truncated, stat err=%v", err)
+	}
+	_ = w.Close()
+
+	w = openTestWAL(t, dir, 100)
+	if w.segIndex == 0 {
+		t.Fatal("reopened behind newer segments")
+	}
+	appendSeqs(t, w, 11, 12)
+	_ = w.Close()
+
+	var prev uint64
+	if _, err := Replay(dir, func(r *Record) error {
+		if r.Seq <= prev {
+			t.Fatalf("non-monotonic seq %d after %d", r.Seq, prev)
+		}
+		prev = r.Seq
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if prev != 12 {
+		t.Fatalf("last seq %d, want 12", prev)
+	}
+}
+
+func TestTruncateKeepsCurrentSegment(t *testing.T) {
+	dir := t.TempDir()
+
+	w := openTestWAL(t, dir, 1<<20)
+	appendSeqs(t, w, 1, 3)
+	if _, err := w.TruncateBefore(3); err != nil {
+		t.Fatal(err)
+	}
+	appendSeqs(t, w, 4, 5)
+	_ = w.Close()
+
+	replaySeqs(t, dir, 5)
+}
+
+func TestTruncateKeepsLastSeq(t *testing.T) {
+	dir := t.TempDir()
+
+	// every append fills its segment, so the live one is empty
+	w := openTestWAL(t, dir, 1)
+	appendSeqs(t, w, 1, 5)
+	if _, err := w.TruncateBefore(5); err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Close()
+
+	w = openTestWAL(t, dir, 1)
+	if got := w.LastSeq(); got != 5 {
+		t.Fatalf("LastSeq after reopen = %d, want 5", got)
+	}
+	_ = w.Close()
+
+	last, err := ReplayFrom(dir, 1, func(r *Record) error {
+		t.Fatalf("replayed seq %d from an emptied log", r.Seq)
+		return nil
+	})
+	if err != nil || last != 5 {
+		t.Fatalf("ReplayFrom: last %d, err %v, want 5", last, err)
+	}
+}
+
+// writeRaw appends b to segment index in dir, as a crash would
+// leave it.
+func writeRaw(t *testing.T, dir string, index int, b []byte) {
+	t.Helper()
+	f, err := os.OpenFile(segmentPath(dir, index), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.Write(b); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReplaySkipsTornTail(t *testing.T) {
+	dir := t.TempDir()
+
+	w := openTestWAL(t, dir, 1<<20)
+	appendSeqs(t, w, 1, 3)
+	_ = w.Close()
+
+	torn := appendFrame(nil, NewRecord(RecordPlace, 4, []byte("payload")))
+	writeRaw(t, dir, 0, torn[:len(torn)/2])
+
+	replaySeqs(t, dir, 3)
+}
+
+func TestReopenTruncatesTornTail(t *testing.T) {
+	dir := t.TempDir()
+
+	// no Close: simulate a crash after the appends
+	w := openTestWAL(t, dir, 1<<20)
+	appendSeqs(t, w, 1, 3)
+
+	// last frame fully written but its bytes never made it out
+	bad := appendFrame(nil, NewRecord(RecordPlace, 4, []byte("payload")))
+	bad[len(bad)-1] ^= 0xff
+	writeRaw(t, dir, 0, bad)
+
+	w = openTestWAL(t, dir, 1<<20)
+	if w.segIndex != 0 {
+		t.Fatalf("reopened at segment %d, want 0", w.segIndex)
+	}
+	appendSeqs(t, w, 4, 5)
+	_ = w.Close()
+
+	replaySeqs(t, dir, 5)
+}
+
+func TestReopenTruncatesZeroFilledTail(t *testing.T) {
+	dir := t.TempDir()
+
+	w := openTestWAL(t, dir, 1<<20)
+	appendSeqs(t, w, 1, 1)
+
+	// blocks allocated but never written before the crash
+	writeRaw(t, dir, 0, make([]byte, 4096))
+
+	w = openTestWAL(t, dir, 1<<20)
+	appendSeqs(t, w, 2, 3)
+	_ = w.Close()
+
+	replaySeqs(t, dir, 3)
+}
+
+func TestReopenTruncatesTornBatch(t *testing.T) {
+	dir := t.TempDir()
+
+	w := openTestWAL(t, dir, 1<<20)
+	appendSeqs(t, w, 1, 3)
+
+	// one AppendBatch of 4..8 that only got partway to disk:
+	// 4 and 5 intact, 6 cut off, then 7 and 8 zero-filled
+	var batch []byte
+	for seq := uint64(4); seq <= 8; seq++ {
+		batch = appendFrame(batch, NewRecord(RecordPlace, seq, []byte("payload")))
+	}
+	frame := frameSize(len("payload"))
+	cut := 2*frame + frame/2
+	clear(batch[cut:])
+	writeRaw(t, dir, 0, batch)
+
+	replaySeqs(t, dir, 5)
+
+	w = openTestWAL(t, dir, 1<<20)
+	appendSeqs(t, w, 6, 7)
+	_ = w.Close()
+
+	replaySeqs(t, dir, 7)
+}
+
+func TestReopenTruncatesGarbageLength(t *testing.T) {
+	for _, l := range []uint32{0xFFFFFFFE, 1 << 30, maxPayload + 1} {
+		dir := t.TempDir()
+
+		w := openTestWAL(t, dir, 1<<20)
+		appendSeqs(t, w, 1, 2)
+
+		// a header whose length no writer could have produced
+		h := make([]byte, 21+2)
+		h[8] = 3
+		binary.BigEndian.PutUint32(h[17:21], l)
+		writeRaw(t, dir, 0, h)
+
+		replaySeqs(t, dir, 2)
+
+		w = openTestWAL(t, dir, 1<<20)
+		appendSeqs(t, w, 3, 4)
+		_ = w.Close()
+
+		replaySeqs(t, dir, 4)
+	}
+}
+
+func TestAppendRefusesOversizedRecord(t *testing.T) {
+	w := openTestWAL(t, t.TempDir(), 1<<20)
+	defer w.Close()
+
+	err := w.Append(NewRecord(RecordPlace, 1, make([]byte, maxPayload+1)))
+	if !errors.Is(err, ErrRecordTooLarge) {
+		t.Fatalf("err = %v, want ErrRecordTooLarge", err)
+	}
+}
+
+func TestReplayReportsMidLogCorruption(t *testing.T) {
+	dir := t.TempDir()
+
+	w := openTestWAL(t, dir, 1<<20)
+	appendSeqs(t, w, 1, 5)
+	_ = w.Close()
+
+	// flip a payload byte of seq 3
+	path := segmentPath(dir, 0)
+	b, _ := os.ReadFile(path)
+	frame := frameSize(len("payload"))
+	b[2*frame+21] ^= 0xff
+	_ = os.WriteFile(path, b, 0o644)
+
+	_, err := Replay(dir, func(*Record) error { return nil })
+
+	var ce *CorruptionError
+	if !errors.As(err, &ce) {
+		t.Fatalf("want CorruptionError, got %v", err)
+	}
+	if ce.Segment != path || ce.Offset != 2*frame || ce.Seq != 2 || !errors.Is(err, ErrChecksum) {
+		t.Fatalf("corruption: %+v", ce)
+	}
+
+	if _, err := Open(Config{Dir: dir, SegmentSize: 1 << 20}); !errors.As(err, &ce) {
+		t.Fatalf("Open on corrupt segment: %v", err)
+	}
+}
+
+func TestReplayReportsTornSealedSegment(t *testing.T) {
+	dir := t.TempDir()
+
+	w := openTestWAL(t, dir, 100)
+	appendSeqs(t, w, 1, 10)
+	_ = w.Close()
+
+	torn := appendFrame(nil, NewRecord(RecordPlace, 99, []byte("payload")))
+	writeRaw(t, dir, 0, torn[:10])
+
+	_, err := Replay(dir, func(*Record) error { return nil })
+
+	var ce *CorruptionError
+	if !errors.As(err, &ce) || ce.Segment != segmentPath(dir, 0) {
+		t.Fatalf("want CorruptionError on segment 0, got %v", err)
+	}
+}
+
+func TestRotateOnAge(t *testing.T) {
+	dir := t.TempDir()
+
+	w, err := Open(Config{Dir: dir, SegmentSize: 1 << 20, SegmentDuration: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendSeqs(t, w, 1, 1)
+	time.Sleep(2 * time.Millisecond)
+	appendSeqs(t, w, 2, 2)
+	_ = w.Close()
+
+	idx, _ := listSegments(dir)
+	if len(idx) < 2 {
+		t.Fatalf("no age rotation: segments %v", idx)
+	}
+	replaySeqs(t, dir, 2)
+}
+
+func TestRotateIdleSegmentOnAge(t *testing.T) {
+	dir := t.TempDir()
+
+	w, err := Open(Config{Dir: dir, SegmentSize: 1 << 20, SegmentDuration: 5 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// no write after this one: only the age check can seal it
+	appendSeqs(t, w, 1, 1)
+	eventually(t, func() bool {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		return len(w.manifest.Segments) == 1
+	})
+
+	n, err := w.TruncateBefore(1)
+	if err != nil || n == 0 {
+		t.Fatalf("idle segment not reclaimed: %d bytes, %v", n, err)
+	}
+}
+
+func TestManifestRanges(t *testing.T) {
+	dir := t.TempDir()
+
+	w := openTestWAL(t, dir, 100)
+	appendSeqs(t, w, 1, 10)
+	_ = w.Close()
+
+	m, err := loadManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var next uint64 = 1
+	for _, r := range m.Segments {
+		if r.FirstSeq != next || r.LastSeq < r.FirstSeq {
+			t.Fatalf("segment %d range %d..%d, want first %d", r.Index, r.FirstSeq, r.LastSeq, next)
+		}
+		next = r.LastSeq + 1
+	}
+
+	// a manifest lost in a crash is rebuilt from the segments
+	_ = os.Remove(filepath.Join(dir, manifestName))
+	w = openTestWAL(t, dir, 100)
+	if got := len(w.manifest.Segments); got != len(m.Segments) {
+		t.Fatalf("rebuilt manifest has %d segments, want %d", got, len(m.Segments))
+	}
+
+	// truncation goes by the manifest and keeps later records
+	if _, err := w.TruncateBefore(6); err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Close()
+
+	var first uint64
+	if _, err := Replay(dir, func(r *Record) error {
+		if first == 0 {
+			first = r.Seq
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if first == 0 || first > 7 {
+		t.Fatalf("truncation dropped live records: first seq %d", first)
+	}
+}
+
+func TestReplayFrom(t *testing.T) {
+	dir := t.TempDir()
+
+	w := openTestWAL(t, dir, 100)
+	appendSeqs(t, w, 1, 20)
+	_ = w.Close()
+
+	var got []uint64
+	last, err := ReplayFrom(dir, 15, func(r *Record) error {
+		got = append(got, r.Seq)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last != 20 || len(got) != 6 || got[0] != 15 {
+		t.Fatalf("ReplayFrom(15): last %d, got %v", last, got)
+	}
+}
+
+func TestTruncateReportsBytes(t *testing.T) {
+	dir := t.TempDir()
+
+	w := openTestWAL(t, dir, 100)
+	defer w.Close()
+	appendSeqs(t, w, 1, 10)
+
+	before, _ := w.RetainedBytes()
+	reclaimed, err := w.TruncateBefore(6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, _ := w.RetainedBytes()
+
+	if reclaimed <= 0 || before-after != reclaimed {
+		t.Fatalf("retained %d -> %d, reclaimed %d", before, after, reclaimed)
+	}
+	if w.LastSeq() != 10 {
+		t.Fatalf("LastSeq %d, want 10", w.LastSeq())
+	}
+}
+
+// countSyncs makes datasync count its calls for the rest of the
+// test. If hold is set, the first sync blocks until it is closed
+// and signals entered first.
+func countSyncs(t *testing.T, entered, hold chan struct{}) *atomic.Int64 {
+	t.Helper()
+	var calls atomic.Int64
+	datasync = func(f *os.File) error {
+		if calls.Add(1) == 1 && hold != nil {
+			close(entered)
+			<-hold
+		}
+		return fdatasync(f)
+	}
+	t.Cleanup(func() { datasync = fdatasync })
+	return &calls
+}
+
+func eventually(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestGroupCommitSharesSync(t *testing.T) {
+	entered, hold := make(chan struct{}), make(chan struct{})
+	calls := countSyncs(t, entered, hold)
+
+	dir := t.TempDir()
+	w, err := Open(Config{Dir: dir, SegmentSize: 1 << 20, Durability: SyncGroupCommit})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := make(chan error, 8)
+	appendAsync := func(seq uint64) {
+		go func() { errs <- w.Append(NewRecord(RecordPlace, seq, []byte("payload"))) }()
+	}
+	queued := func() int {
+		w.gmu.Lock()
+		defer w.gmu.Unlock()
+		return len(w.waiters)
+	}
+
+	// seq 1 is mid-sync; 2..8 queue up behind it in order
+	appendAsync(1)
+	<-entered
+	for seq := uint64(2); seq <= 8; seq++ {
+		appendAsync(seq)
+		eventually(t, func() bool { return queued() == int(seq-1) })
+	}
+	close(hold)
+
+	for range 8 {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("%d syncs for 8 appends, want 2", n)
+	}
+	_ = w.Close()
+
+	replaySeqs(t, dir, 8)
+}
+
+func TestIntervalModeSyncsInBackground(t *testing.T) {
+	calls := countSyncs(t, nil, nil)
+
+	dir := t.TempDir()
+	w, err := Open(Config{Dir: dir, SegmentSize: 1 << 20, Durability: SyncInterval, SyncInterval: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	appendSeqs(t, w, 1, 3)
+	eventually(t, func() bool {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		return !w.dirty
+	})
+	if calls.Load() == 0 {
+		t.Fatal("dirty segment never synced")
+	}
+}
+
+func TestCloseFlushes(t *testing.T) {
+	calls := countSyncs(t, nil, nil)
+
+	dir := t.TempDir()
+	w, err := Open(Config{Dir: dir, SegmentSize: 1 << 20, Durability: SyncInterval, SyncInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendSeqs(t, w, 1, 3)
+	if n := calls.Load(); n != 0 {
+		t.Fatalf("interval mode synced %d times on append", n)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("Close synced %d times, want 1", n)
+	}
+	replaySeqs(t, dir, 3)
+}
@@ -2,6 +2,7 @@ package exit
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -16,8 +17,11 @@ const (
 	ExitAcked
 )
 
+// ExitRecord is one outbound event. A single command may emit
+// several events; they share Seq and are ordered by Sub.
 type ExitRecord struct {
 	Seq       uint64    `json:"seq"`
+	Sub       uint32    `json:"sub,omitempty"`
 	Payload   []byte    `json:"payload"`
 	State     ExitState `json:"state"`
 	Timestamp int64     `json:"ts"`
@@ -43,8 +47,13 @@ func (w *ExitWAL) Close() error {
 	return w.db.Close()
 }
 
-func key(seq uint64) []byte {
-	return []byte(fmt.Sprintf("exit/%020d", seq))
+// key keeps the original single-event layout for Sub 0 so
+// records written before multi-event support stay addressable.
+func key(seq uint64, sub uint32) []byte {
+	if sub == 0 {
+		return []byte(fmt.Sprintf("exit/%020d", seq))
+	}
+	return []byte(fmt.Sprintf("exit/%020d/%06d", seq, sub))
 }
 
 // ===================================================
@@ -59,19 +68,42 @@ func (w *ExitWAL) PutNew(seq uint64, payload []byte) error {
 		Timestamp: time.Now().UnixNano(),
 	}
 	data, _ := json.Marshal(rec)
-	return w.db.Set(key(seq), data, pebble.Sync)
+	return w.db.Set(key(seq, 0), data, pebble.Sync)
 }
 
-func (w *ExitWAL) MarkSent(seq uint64) error {
-	return w.updateState(seq, ExitSent)
+// PutNewBatch atomically stores all events produced by one
+// command, in order, with a single sync.
+func (w *ExitWAL) PutNewBatch(seq uint64, payloads [][]byte) error {
+	batch := w.db.NewBatch()
+	defer batch.Close()
+
+	now := time.Now().UnixNano()
+	for i, p := range payloads {
+		rec := ExitRecord{
+			Seq:       seq,
+			Sub:       uint32(i),
+			Payload:   p,
+			State:     ExitNew,
+			Timestamp: now,
+		}
+		data, _ := json.Marshal(rec)
+		if err := batch.Set(key(seq, uint32(i)), data, nil); err != nil {
+			return err
+		}
+	}
+	return batch.Commit(pebble.Sync)
 }
 
-func (w *ExitWAL) MarkAcked(seq uint64) error {
-	return w.updateState(seq, ExitAcked)
+func (w *ExitWAL) MarkSent(seq uint64, sub uint32) error {
+	return w.updateState(seq, sub, ExitSent)
 }
 
-func (w *ExitWAL) updateState(seq uint64, st ExitState) error {
-	k := key(seq)
+func (w *ExitWAL) MarkAcked(seq uint64, sub uint32) error {
+	return w.updateState(seq, sub, ExitAcked)
+}
+
+func (w *ExitWAL) updateState(seq uint64, sub uint32, st ExitState) error {
+	k := key(seq, sub)
 
 	val, closer, err := w.db.Get(k)
 	if err != nil {
@@ -120,6 +152,25 @@ func (w *ExitWAL) ScanPending(fn func(*ExitRecord) error) error {
 	return nil
 }
 
+// LowestPending returns the seq of the oldest event not yet
+// acked, if any.
+func (w *ExitWAL) LowestPending() (uint64, bool, error) {
+	var (
+		seq   uint64
+		found bool
+	)
+	err := w.ScanPending(func(rec *ExitRecord) error {
+		seq, found = rec.Seq, true
+		return errStopScan
+	})
+	if err == errStopScan {
+		err = nil
+	}
+	return seq, found, err
+}
+
+var errStopScan = errors.New("exit: stop scan")
+
 // ===================================================
 // TRUNCATION (GC)
 // ===================================================
@@ -17,11 +17,14 @@ type Broadcaster struct {
 	topic    string
 }
 
+// Event is the envelope common to every outbox payload. ID and
+// Seq are only unique together with Shard.
 type Event struct {
-	V    int    `json:"v"`
-	Type string `json:"type"`
-	ID   uint64 `json:"id"`
-	Seq  uint64 `json:"seq"`
+	V     int    `json:"v"`
+	Shard int    `json:"shard"`
+	Type  string `json:"type"`
+	ID    uint64 `json:"id"`
+	Seq   uint64 `json:"seq"`
 }
 
 // ------------------------------------------------
@@ -82,7 +85,7 @@ func (b *Broadcaster) replayOnce() {
 	_ = b.exitWAL.ScanPending(func(rec *exitwal.ExitRecord) error {
 
 		// 1️⃣ Mark SENT (idempotent)
-		_ = b.exitWAL.MarkSent(rec.Seq)
+		_ = b.exitWAL.MarkSent(rec.Seq, rec.Sub)
 
 		// 2️⃣ Publish to Kafka
 		msg := &sarama.ProducerMessage{
@@ -96,7 +99,7 @@ func (b *Broadcaster) replayOnce() {
 		}
 
 		// 3️⃣ Mark ACKED
-		_ = b.exitWAL.MarkAcked(rec.Seq)
+		_ = b.exitWAL.MarkAcked(rec.Seq, rec.Sub)
 
 		return nil
 	})
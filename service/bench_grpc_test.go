@@ -29,6 +29,7 @@ func BenchmarkGRPCPlaceOrder(b *testing.B) {
 				Price:  100,
 				Qty:    1,
 				UserId: 1,
+				Symbol: "DEFAULT",
 			})
 			if err != nil {
 				b.Fatal(err)
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"hash/fnv"
+	"sort"
+
+	"loki/domain/orderbook"
+)
+
+/*
+Engine — sharded front door over several OrderServices.
+
+Each shard owns the symbols that hash to it, plus its own
+sequencer, entry WAL, exit WAL and single writer goroutine.
+Books never span shards, so every book stays deterministic
+while throughput scales with the number of shards.
+
+The shard count is part of the on-disk layout: changing it
+re-homes symbols and must not happen with data present.
+*/
+
+type Engine struct {
+	shards []*OrderService
+}
+
+// ShardIndex maps a symbol to the shard that owns it.
+func ShardIndex(symbol string, shards int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(symbol))
+	return int(h.Sum32() % uint32(shards))
+}
+
+// NewEngine routes over shards; shards[i] must have been built
+// (and replayed) for shard i.
+func NewEngine(shards ...*OrderService) *Engine {
+	return &Engine{shards: shards}
+}
+
+// Close stops every shard writer. In-flight commands finish first.
+func (e *Engine) Close() {
+	for _, s := range e.shards {
+		s.Close()
+	}
+}
+
+// Shards exposes the per-shard services for background jobs.
+func (e *Engine) Shards() []*OrderService {
+	return e.shards
+}
+
+func (e *Engine) route(symbol string) *OrderService {
+	return e.shards[ShardIndex(symbol, len(e.shards))]
+}
+
+// -------------------- COMMAND --------------------
+
+func (e *Engine) PlaceOrder(
+	symbol string,
+	side orderbook.Side,
+	otype orderbook.OrderType,
+	price int64,
+	qty int64,
+	userID uint64,
+) (ExecutionReport, error) {
+	return e.route(symbol).PlaceOrder(symbol, side, otype, price, qty, userID)
+}
+
+func (e *Engine) CancelOrder(symbol string, orderID uint64) (uint64, error) {
+	return e.route(symbol).CancelOrder(symbol, orderID)
+}
+
+func (e *Engine) AmendOrder(symbol string, orderID uint64, price, qty int64) (ExecutionReport, error) {
+	return e.route(symbol).AmendOrder(symbol, orderID, price, qty)
+}
+
+func (e *Engine) CreateInstrument(inst orderbook.Instrument) (uint64, error) {
+	return e.route(inst.Symbol).CreateInstrument(inst)
+}
+
+// -------------------- QUERY --------------------
+
+// Instruments lists the instruments of every shard, sorted by symbol.
+func (e *Engine) Instruments() []orderbook.Instrument {
+	var out []orderbook.Instrument
+	for _, s := range e.shards {
+		out = append(out, s.Instruments()...)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Symbol < out[j].Symbol
+	})
+	return out
+}
+
+func (e *Engine) GetOrder(symbol string, id uint64) (orderbook.Order, bool, error) {
+	return e.route(symbol).GetOrder(symbol, id)
+}
+
+func (e *Engine) Snapshot(symbol string) ([]orderbook.Order, error) {
+	return e.route(symbol).Snapshot(symbol)
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"errors"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"loki/domain/orderbook"
+)
+
+// TestShardIndexStable pins the routing: it is part of the
+// on-disk layout, so any change here strands existing data.
+func TestShardIndexStable(t *testing.T) {
+	cases := []struct {
+		symbol string
+		shards int
+		want   int
+	}{
+		{orderbook.DefaultSymbol, 4, 2},
+		{"BTC-USD", 4, 1},
+		{"ETH-USD", 4, 1},
+		{"SOL-USD", 4, 0},
+		{orderbook.DefaultSymbol, 16, 14},
+		{"SOL-USD", 16, 12},
+	}
+	for _, c := range cases {
+		if got := ShardIndex(c.symbol, c.shards); got != c.want {
+			t.Errorf("ShardIndex(%q, %d) = %d, want %d", c.symbol, c.shards, got, c.want)
+		}
+	}
+}
+
+func TestEngineIsolatesShards(t *testing.T) {
+	dir := t.TempDir()
+
+	shards := make([]*testShard, 4)
+	svcs := make([]*OrderService, len(shards))
+	for i := range shards {
+		sh, err := openTestShardAt(t, filepath.Join(dir, strconv.Itoa(i)), i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sh.close()
+		shards[i], svcs[i] = sh, sh.svc
+	}
+	engine := NewEngine(svcs...)
+
+	const symbol = "SOL-USD"
+	owner := ShardIndex(symbol, len(shards))
+
+	if _, err := engine.CreateInstrument(orderbook.Instrument{Symbol: symbol, TickSize: 1}); err != nil {
+		t.Fatal(err)
+	}
+	rep, err := engine.PlaceOrder(symbol, orderbook.Bid, orderbook.Limit, 100, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := engine.GetOrder(symbol, rep.OrderID); err != nil || !ok {
+		t.Fatalf("order not found through the engine: %v", err)
+	}
+
+	// IDs repeat across shards, so every event names its shard
+	evs := shards[owner].events(t, rep.Seq)
+	if len(evs) == 0 {
+		t.Fatal("no outbox events for the order")
+	}
+	for _, ev := range evs {
+		if ev["shard"] != float64(owner) {
+			t.Fatalf("event %v: want shard %d", ev, owner)
+		}
+	}
+
+	for i, sh := range shards {
+		if i == owner {
+			if sh.books.Get(symbol) == nil || sh.seq.Current() != 2 {
+				t.Fatalf("owner shard %d: book or seq missing", i)
+			}
+			continue
+		}
+		if sh.books.Get(symbol) != nil || sh.seq.Current() != 0 {
+			t.Fatalf("shard %d saw %s", i, symbol)
+		}
+		if _, err := sh.svc.PlaceOrder(symbol, orderbook.Bid, orderbook.Limit, 100, 1, 1); !errors.Is(err, orderbook.ErrUnknownSymbol) {
+			t.Fatalf("shard %d traded %s: %v", i, symbol, err)
+		}
+		if _, ok, _ := sh.svc.GetOrder(symbol, rep.OrderID); ok {
+			t.Fatalf("shard %d returned another shard's order", i)
+		}
+	}
+}
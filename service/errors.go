@@ -0,0 +1,87 @@
+package service
+
+import (
+	"errors"
+
+	"loki/domain/orderbook"
+)
+
+/*
+Errors returned by OrderService and Engine.
+
+Every command error is one of the kinds below, wrapped in an
+*Error together with its cause, so callers can test either:
+errors.Is(err, ErrUnknownOrder) and
+errors.Is(err, orderbook.ErrOrderNotFound) both hold.
+
+Book rejections of a sequenced order (FOK not fillable, post-only
+would cross, already filled) are outcomes, not failures: they
+carry their orderbook error unwrapped next to a report.
+
+Orders that break their instrument's rules (tick, lot, min/max
+qty, min notional, price band) are sequenced too, so they are
+journaled and emit a REJECTED event, but come back as
+ErrValidation.
+*/
+
+var (
+	// ErrQueueFull is returned when the writer cannot keep up.
+	// Clients should back off and retry.
+	ErrQueueFull = errors.New("service: command queue full")
+
+	// ErrValidation rejects a request that can never succeed
+	// as sent: bad symbol, price, quantity, amend or instrument.
+	ErrValidation = errors.New("service: invalid request")
+
+	// ErrWALUnavailable is returned to the commands whose entry
+	// WAL append failed. The shard halts right after.
+	ErrWALUnavailable = errors.New("service: entry WAL unavailable")
+
+	// ErrHalted refuses writes once a shard has halted. Reads
+	// keep working; a restart runs recovery.
+	ErrHalted = errors.New("service: halted, read-only")
+
+	ErrDuplicate    = errors.New("service: duplicate")
+	ErrUnknownOrder = errors.New("service: unknown order")
+)
+
+// Error pairs a service error kind with the error that caused it.
+type Error struct {
+	Kind error
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Kind.Error() + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// classify maps domain errors onto service error kinds.
+func classify(err error) error {
+	var kind error
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, new(*Error)):
+		return err
+	case errors.Is(err, orderbook.ErrOrderNotFound):
+		kind = ErrUnknownOrder
+	case errors.Is(err, orderbook.ErrInstrumentExists),
+		errors.Is(err, orderbook.ErrDuplicateOrder):
+		kind = ErrDuplicate
+	case errors.Is(err, orderbook.ErrInvalidSymbol),
+		errors.Is(err, orderbook.ErrUnknownSymbol),
+		errors.Is(err, orderbook.ErrInvalidAmend),
+		errors.Is(err, orderbook.ErrInvalidInstrument),
+		orderbook.IsRuleViolation(err):
+		kind = ErrValidation
+	default:
+		return err
+	}
+
+	return &Error{Kind: kind, Err: err}
+}
@@ -0,0 +1,108 @@
+package service
+
+import "loki/domain/orderbook"
+
+// ExecStatus is the final state of an order once PlaceOrder returns.
+type ExecStatus int
+
+const (
+	// ExecResting: no fills, the whole order rests on the book.
+	ExecResting ExecStatus = iota
+	// ExecPartiallyFilled: some fills, the remainder rests.
+	ExecPartiallyFilled
+	// ExecFilled: the full quantity traded.
+	ExecFilled
+	// ExecCanceled: an IOC or market remainder that could not
+	// trade and was dropped instead of resting.
+	ExecCanceled
+	// ExecRejected: the order never reached the book.
+	ExecRejected
+)
+
+func (s ExecStatus) String() string {
+	switch s {
+	case ExecResting:
+		return "RESTING"
+	case ExecPartiallyFilled:
+		return "PARTIALLY_FILLED"
+	case ExecFilled:
+		return "FILLED"
+	case ExecCanceled:
+		return "CANCELED"
+	case ExecRejected:
+		return "REJECTED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Fill is one execution against a resting maker.
+type Fill struct {
+	MakerID uint64
+	Price   int64
+	Qty     int64
+}
+
+// ExecutionReport is what PlaceOrder reports back to the caller.
+type ExecutionReport struct {
+	Seq     uint64
+	OrderID uint64
+	Status  ExecStatus
+
+	// Price is the price the order was accepted at. It differs
+	// from the requested price only when Repriced is set.
+	Price    int64
+	Repriced bool
+
+	// FilledQty is cumulative; AvgPrice and Fills only cover
+	// executions caused by this command.
+	FilledQty    int64
+	RemainingQty int64
+	AvgPrice     float64
+	Fills        []Fill
+}
+
+// newExecutionReport summarises o after matching. trades are
+// copied because the book reuses their backing array.
+func newExecutionReport(
+	o *orderbook.Order,
+	requested int64,
+	trades []orderbook.Trade,
+	rested bool,
+) ExecutionReport {
+	r := ExecutionReport{
+		Seq:          o.SeqID,
+		OrderID:      o.ID,
+		Price:        o.Price,
+		Repriced:     o.Price != requested,
+		FilledQty:    o.Filled,
+		RemainingQty: o.Remaining(),
+	}
+
+	if len(trades) > 0 {
+		r.Fills = make([]Fill, len(trades))
+
+		// float64 per fill: price × qty can overflow int64
+		var notional float64
+		var traded int64
+		for i, t := range trades {
+			r.Fills[i] = Fill{MakerID: t.MakerID, Price: t.Price, Qty: t.Qty}
+			notional += float64(t.Price) * float64(t.Qty)
+			traded += t.Qty
+		}
+		r.AvgPrice = notional / float64(traded)
+	}
+
+	switch {
+	case o.Remaining() == 0:
+		r.Status = ExecFilled
+	case !rested:
+		r.Status = ExecCanceled
+	case o.Filled > 0:
+		r.Status = ExecPartiallyFilled
+	default:
+		r.Status = ExecResting
+	}
+
+	return r
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"loki/domain/orderbook"
+)
+
+func TestExecutionReportStatus(t *testing.T) {
+	cases := []struct {
+		name  string
+		order orderbook.Order
+		want  ExecStatus
+	}{
+		{"resting", orderbook.Order{Side: orderbook.Bid, Type: orderbook.Limit, Price: 99, Qty: 5}, ExecResting},
+		{"partially filled", orderbook.Order{Side: orderbook.Bid, Type: orderbook.Limit, Price: 101, Qty: 8}, ExecPartiallyFilled},
+		{"filled", orderbook.Order{Side: orderbook.Bid, Type: orderbook.Limit, Price: 101, Qty: 4}, ExecFilled},
+		{"IOC remainder", orderbook.Order{Side: orderbook.Bid, Type: orderbook.IOC, Price: 101, Qty: 8}, ExecCanceled},
+		{"market remainder", orderbook.Order{Side: orderbook.Bid, Type: orderbook.Market, Qty: 8}, ExecCanceled},
+	}
+
+	for _, c := range cases {
+		book := orderbook.NewOrderBook()
+		book.Place(&orderbook.Order{ID: 1, SeqID: 1, Side: orderbook.Ask, Type: orderbook.Limit, Price: 100, Qty: 2})
+		book.Place(&orderbook.Order{ID: 2, SeqID: 2, Side: orderbook.Ask, Type: orderbook.Limit, Price: 101, Qty: 4})
+
+		o := c.order
+		o.ID, o.SeqID = 3, 3
+		requested := o.Price
+		trades, err := book.Place(&o)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		r := newExecutionReport(&o, requested, trades, book.Get(o.ID) == &o)
+		if r.Status != c.want {
+			t.Errorf("%s: status %v, want %v", c.name, r.Status, c.want)
+		}
+		if r.FilledQty+r.RemainingQty != o.Qty || len(r.Fills) != len(trades) {
+			t.Errorf("%s: report %+v", c.name, r)
+		}
+	}
+}
+
+func TestExecutionReportAvgPriceNoOverflow(t *testing.T) {
+	o := &orderbook.Order{ID: 1, SeqID: 1, Price: math.MaxInt64 / 2, Qty: 4, Filled: 4}
+	trades := []orderbook.Trade{
+		{MakerID: 2, Price: math.MaxInt64 / 2, Qty: 2},
+		{MakerID: 3, Price: math.MaxInt64 / 2, Qty: 2},
+	}
+
+	r := newExecutionReport(o, o.Price, trades, false)
+	if want := float64(math.MaxInt64 / 2); math.Abs(r.AvgPrice-want)/want > 1e-12 {
+		t.Fatalf("avg price %g, want %g", r.AvgPrice, want)
+	}
+}
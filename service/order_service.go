@@ -2,7 +2,8 @@ package service
 
 import (
 	"encoding/json"
-	"fmt"
+	"sync"
+	"sync/atomic"
 
 	"loki/domain/orderbook"
 	"loki/infra/memory"
@@ -15,16 +16,33 @@ import (
 /*
 OrderService — single write entrypoint.
 
-STRICT ORDER (NON-NEGOTIABLE):
-1) seq := Sequencer.Next()
+All commands and queries are executed by ONE writer goroutine
+that drains a bounded inbox in batches. Callers block until
+their command has been applied.
+
+STRICT ORDER (NON-NEGOTIABLE), per batch:
+1) seq := Sequencer.Next()      (in inbox order)
 2) Entry WAL append (durability)
-3) Execute matching
+3) Execute matching             (in seq order)
 4) Exit WAL write (outbox)
 5) Respond to client
 */
 
+const (
+	// inboxSize bounds the number of queued commands. When it
+	// is full, callers get ErrQueueFull instead of piling up.
+	inboxSize = 4096
+
+	// maxBatch bounds how many commands share one WAL append.
+	maxBatch = 256
+)
+
 type OrderService struct {
-	book   *orderbook.OrderBook
+	// shard is this service's index in the Engine. It is stamped
+	// on every outbox event; see PAYLOAD BUILDING.
+	shard int
+
+	books  *orderbook.Registry
 	pool   *memory.Pool[orderbook.Order]
 	ring   *memory.RetireRing
 	reader *snapshot.Reader
@@ -32,12 +50,33 @@ type OrderService struct {
 	seqGen   *sequence.Sequencer
 	entryWAL *entrywal.WAL
 	exitWAL  *exitwal.ExitWAL
+
+	inbox chan *command
+	done  chan struct{}
+
+	// applied is the seq of the last command executed. Only the
+	// writer goroutine touches it.
+	applied uint64
+
+	// halted is set for good once the entry WAL fails. haltErr
+	// is written before it and never after.
+	halted  atomic.Bool
+	haltErr error
+
+	// background jobs, stopped by Close before the writer
+	quit chan struct{}
+	jobs sync.WaitGroup
+
+	snapMetrics snapshotMetrics
 }
 
 // -------------------- CONSTRUCTOR --------------------
 
+// NewOrderService starts the writer goroutine. Replay must
+// have completed before it is called.
 func NewOrderService(
-	book *orderbook.OrderBook,
+	shard int,
+	books *orderbook.Registry,
 	pool *memory.Pool[orderbook.Order],
 	ring *memory.RetireRing,
 	reader *snapshot.Reader,
@@ -45,105 +84,170 @@ func NewOrderService(
 	entryWAL *entrywal.WAL,
 	exitWAL *exitwal.ExitWAL,
 ) *OrderService {
-	return &OrderService{
-		book:     book,
+	s := &OrderService{
+		shard:    shard,
+		books:    books,
 		pool:     pool,
 		ring:     ring,
 		reader:   reader,
 		seqGen:   seqGen,
 		entryWAL: entryWAL,
 		exitWAL:  exitWAL,
+		inbox:    make(chan *command, inboxSize),
+		done:     make(chan struct{}),
+		applied:  seqGen.Current(),
+		quit:     make(chan struct{}),
 	}
+	go s.run()
+	return s
+}
+
+// Close stops background jobs, then the writer after it has
+// drained the inbox. No command may be submitted afterwards.
+func (s *OrderService) Close() {
+	close(s.quit)
+	s.jobs.Wait()
+
+	close(s.inbox)
+	<-s.done
 }
 
 // -------------------- COMMAND --------------------
 
-// PlaceOrder is the ONLY mutation entrypoint.
+// PlaceOrder is the ONLY order entrypoint.
 // It is crash-safe, replay-safe, and outbox-safe.
 func (s *OrderService) PlaceOrder(
+	symbol string,
 	side orderbook.Side,
 	otype orderbook.OrderType,
 	price int64,
 	qty int64,
 	userID uint64,
-) uint64 {
-	// 1️⃣ Generate global sequence ID
-	seq := s.seqGen.Next()
-
-	// 2️⃣ Persist intent (ENTRY WAL)
-	err := s.entryWAL.Append(
-		entrywal.NewRecord(
-			entrywal.RecordPlace,
-			seq,
-			[]byte(fmt.Sprintf(
-				"%d|%d|%d|%d|%d",
-				userID,
-				side,
-				otype,
-				price,
-				qty,
-			)),
-		),
-	)
-	if err != nil {
-		// HARD FAIL: client must retry
-		panic(fmt.Errorf("entry WAL append failed: %w", err))
+) (ExecutionReport, error) {
+	c := &command{
+		kind:   cmdPlace,
+		symbol: symbol,
+		side:   side,
+		otype:  otype,
+		price:  price,
+		qty:    qty,
+		userID: userID,
 	}
-
-	// 3️⃣ Execute matching
-	o := s.pool.Get()
-	*o = orderbook.Order{
-		ID:     seq,
-		Side:   side,
-		Type:   otype,
-		Price:  price,
-		Qty:    qty,
-		SeqID:  seq,
-		Status: orderbook.Active,
+	if err := s.submit(c); err != nil {
+		return ExecutionReport{Status: ExecRejected}, err
 	}
+	return c.report, classify(c.err)
+}
 
-	s.book.Place(o)
+// CancelOrder removes a resting order from the book.
+// The cancel is journaled even if the order is gone, so
+// replay reaches the exact same outcome.
+func (s *OrderService) CancelOrder(symbol string, orderID uint64) (uint64, error) {
+	c := &command{
+		kind:    cmdCancel,
+		symbol:  symbol,
+		orderID: orderID,
+	}
+	if err := s.submit(c); err != nil {
+		return 0, err
+	}
+	return c.seq, classify(c.err)
+}
 
-	// 4️⃣ Emit outbox event (EXIT WAL)
-	payload := s.buildOrderAcceptedPayload(o)
-	if err := s.exitWAL.PutNew(seq, payload); err != nil {
-		// Non-blocking: broadcaster will retry
-		fmt.Printf("[WARN] exit WAL write failed for seq %d: %v\n", seq, err)
+// AmendOrder changes the price and/or total quantity of a
+// resting order. A quantity decrease keeps queue priority; a
+// price change or quantity increase re-enters matching.
+func (s *OrderService) AmendOrder(
+	symbol string,
+	orderID uint64,
+	price int64,
+	qty int64,
+) (ExecutionReport, error) {
+	c := &command{
+		kind:    cmdAmend,
+		symbol:  symbol,
+		orderID: orderID,
+		price:   price,
+		qty:     qty,
+	}
+	if err := s.submit(c); err != nil {
+		return ExecutionReport{Status: ExecRejected}, err
 	}
+	return c.report, classify(c.err)
+}
 
-	// 5️⃣ Retire immediately if filled
-	if o.Remaining() == 0 {
-		s.retire(o)
+// CreateInstrument registers a new symbol at runtime. It is
+// journaled like any other command so replay rebuilds the
+// same registry before the first order that references it.
+func (s *OrderService) CreateInstrument(inst orderbook.Instrument) (uint64, error) {
+	c := &command{
+		kind:   cmdInstrument,
+		symbol: inst.Symbol,
+		inst:   inst,
+	}
+	if err := s.submit(c); err != nil {
+		return 0, err
 	}
+	return c.seq, classify(c.err)
+}
 
-	return seq
+// Halted returns why the service stopped taking writes, or nil
+// while it is healthy.
+func (s *OrderService) Halted() error {
+	if !s.halted.Load() {
+		return nil
+	}
+	return s.haltErr
 }
 
 // -------------------- QUERY --------------------
 
-func (s *OrderService) Snapshot() []*orderbook.Order {
-	s.reader.Begin()
-	defer s.reader.End()
-
-	out := make([]*orderbook.Order, 0, 1024)
+// Instruments lists every tradable symbol.
+func (s *OrderService) Instruments() []orderbook.Instrument {
+	return s.books.Instruments()
+}
 
-	s.book.BidsWalk(func(lvl *orderbook.PriceLevel) {
-		for o := lvl.Head(); o != nil; o = o.Next() {
-			if o.Status == orderbook.Active {
-				out = append(out, o)
-			}
+// GetOrder returns a copy of a resting order, if any. It fails
+// with ErrQueueFull when the writer is too busy to answer.
+func (s *OrderService) GetOrder(symbol string, id uint64) (o orderbook.Order, ok bool, err error) {
+	err = s.query(func() {
+		book := s.books.Get(symbol)
+		if book == nil {
+			return
+		}
+		if live := book.Get(id); live != nil {
+			o, ok = *live, true
 		}
 	})
+	return o, ok, err
+}
+
+// Snapshot returns copies of every active order of symbol,
+// bids first (best to worst), then asks. It fails with
+// ErrQueueFull when the writer is too busy to answer.
+func (s *OrderService) Snapshot(symbol string) ([]orderbook.Order, error) {
+	var out []orderbook.Order
+
+	err := s.query(func() {
+		book := s.books.Get(symbol)
+		if book == nil {
+			return
+		}
 
-	s.book.AsksWalk(func(lvl *orderbook.PriceLevel) {
-		for o := lvl.Head(); o != nil; o = o.Next() {
-			if o.Status == orderbook.Active {
-				out = append(out, o)
+		out = make([]orderbook.Order, 0, 1024)
+		collect := func(lvl *orderbook.PriceLevel) {
+			for o := lvl.Head(); o != nil; o = o.Next() {
+				if o.Status == orderbook.Active {
+					out = append(out, *o)
+				}
 			}
 		}
+
+		book.BidsWalk(collect)
+		book.AsksWalk(collect)
 	})
 
-	return out
+	return out, err
 }
 
 // -------------------- MEMORY RECLAMATION --------------------
@@ -163,18 +267,139 @@ func (s *OrderService) retire(o *orderbook.Order) {
 
 // -------------------- PAYLOAD BUILDING --------------------
 
+/*
+Seqs and order IDs are per shard: every shard counts from 1, and
+all shards publish to the same topic. Every event therefore
+carries "shard", and consumers must key on it:
+
+- the events of one command by (shard, seq), in publish order
+- an order, maker or taker by (shard, id)
+*/
+
 // buildOrderAcceptedPayload creates an immutable,
 // versioned event for Kafka / downstream consumers.
-func (s *OrderService) buildOrderAcceptedPayload(o *orderbook.Order) []byte {
+// requested is the client's price, kept when a post-only
+// order was slid to a different one.
+func (s *OrderService) buildOrderAcceptedPayload(symbol string, o *orderbook.Order, requested int64) []byte {
+	event := map[string]any{
+		"v":      1,
+		"shard":  s.shard,
+		"type":   "ORDER_ACCEPTED",
+		"symbol": symbol,
+		"seq":    o.SeqID,
+		"id":     o.ID,
+		"side":   o.Side,
+		"otype":  o.Type,
+		"price":  o.Price,
+		"qty":    o.Qty,
+	}
+	if o.Price != requested {
+		event["repriced"] = true
+		event["requested_price"] = requested
+	}
+
+	b, _ := json.Marshal(event)
+	return b
+}
+
+// buildOrderRejectedPayload records an order that never
+// reached the book.
+func (s *OrderService) buildOrderRejectedPayload(symbol string, o *orderbook.Order, reason error) []byte {
+	event := map[string]any{
+		"v":      1,
+		"shard":  s.shard,
+		"type":   "ORDER_REJECTED",
+		"symbol": symbol,
+		"seq":    o.SeqID,
+		"id":     o.ID,
+		"side":   o.Side,
+		"otype":  o.Type,
+		"price":  o.Price,
+		"qty":    o.Qty,
+		"reason": RejectReason(reason),
+	}
+
+	b, _ := json.Marshal(event)
+	return b
+}
+
+// buildOrderAmendedPayload reports the order's new terms and
+// whether it kept its place in the queue.
+func (s *OrderService) buildOrderAmendedPayload(symbol string, seq uint64, o *orderbook.Order) []byte {
+	event := map[string]any{
+		"v":             1,
+		"shard":         s.shard,
+		"type":          "ORDER_AMENDED",
+		"symbol":        symbol,
+		"seq":           seq,
+		"id":            o.ID,
+		"side":          o.Side,
+		"price":         o.Price,
+		"qty":           o.Qty,
+		"filled":        o.Filled,
+		"kept_priority": o.SeqID != seq,
+	}
+
+	b, _ := json.Marshal(event)
+	return b
+}
+
+// buildTradePayload describes one execution. Settlement
+// consumes these, so fields are never renamed within a version.
+func (s *OrderService) buildTradePayload(symbol string, t *orderbook.Trade) []byte {
 	event := map[string]any{
-		"v":     1,
-		"type":  "ORDER_ACCEPTED",
-		"seq":   o.SeqID,
-		"id":    o.ID,
-		"side":  o.Side,
-		"otype": o.Type,
-		"price": o.Price,
-		"qty":   o.Qty,
+		"v":         1,
+		"shard":     s.shard,
+		"type":      "TRADE",
+		"symbol":    symbol,
+		"seq":       t.Seq,
+		"maker_id":  t.MakerID,
+		"taker_id":  t.TakerID,
+		"price":     t.Price,
+		"qty":       t.Qty,
+		"aggressor": t.Aggressor,
+	}
+
+	b, _ := json.Marshal(event)
+	return b
+}
+
+// buildOrderCanceledPayload reports the quantity that was
+// left on the book when the order was pulled.
+func (s *OrderService) buildOrderCanceledPayload(symbol string, seq uint64, o *orderbook.Order) []byte {
+	event := map[string]any{
+		"v":         1,
+		"shard":     s.shard,
+		"type":      "ORDER_CANCELED",
+		"symbol":    symbol,
+		"seq":       seq,
+		"id":        o.ID,
+		"side":      o.Side,
+		"price":     o.Price,
+		"qty":       o.Qty,
+		"filled":    o.Filled,
+		"remaining": o.Remaining(),
+	}
+
+	b, _ := json.Marshal(event)
+	return b
+}
+
+func (s *OrderService) buildInstrumentCreatedPayload(seq uint64, inst orderbook.Instrument) []byte {
+	event := map[string]any{
+		"v":         1,
+		"shard":     s.shard,
+		"type":      "INSTRUMENT_CREATED",
+		"symbol":    inst.Symbol,
+		"seq":       seq,
+		"tick_size": inst.TickSize,
+		"post_only": inst.PostOnly,
+
+		"lot_size":       inst.Rules.LotSize,
+		"min_qty":        inst.Rules.MinQty,
+		"max_qty":        inst.Rules.MaxQty,
+		"min_notional":   inst.Rules.MinNotional,
+		"price_band_bps": inst.Rules.PriceBandBps,
 	}
 
 	b, _ := json.Marshal(event)
@@ -1,7 +1,10 @@
 package service
 
 import (
+	"sort"
+	"sync"
 	"testing"
+	"time"
 
 	"loki/domain/orderbook"
 	"loki/infra/memory"
@@ -11,8 +14,9 @@ import (
 	"loki/snapshot"
 )
 
-func BenchmarkPlaceOrder_Core(b *testing.B) {
-	book := orderbook.NewOrderBook()
+func newBenchService(b *testing.B, mode entrywal.SyncMode) *OrderService {
+	books := orderbook.NewRegistry()
+	_, _ = books.Create(orderbook.Instrument{Symbol: orderbook.DefaultSymbol, TickSize: 1})
 
 	pool := memory.NewPool(func() *orderbook.Order {
 		return &orderbook.Order{}
@@ -22,14 +26,20 @@ func BenchmarkPlaceOrder_Core(b *testing.B) {
 	seq := sequence.New(0)
 	reader := snapshot.NewReader()
 
-	entryWAL, _ := entrywal.Open(entrywal.Config{
-		Dir:         b.TempDir(),
-		SegmentSize: 64 << 20,
+	entryWAL, err := entrywal.Open(entrywal.Config{
+		Dir:          b.TempDir(),
+		SegmentSize:  64 << 20,
+		Durability:   mode,
+		SyncInterval: 5 * time.Millisecond,
 	})
+	if err != nil {
+		b.Fatal(err)
+	}
 	exitWAL, _ := exitwal.Open(b.TempDir())
 
 	svc := NewOrderService(
-		book,
+		0,
+		books,
 		pool,
 		ring,
 		reader,
@@ -37,11 +47,21 @@ func BenchmarkPlaceOrder_Core(b *testing.B) {
 		entryWAL,
 		exitWAL,
 	)
+	b.Cleanup(func() {
+		svc.Close()
+		_ = entryWAL.Close()
+	})
+	return svc
+}
+
+func BenchmarkPlaceOrder_Core(b *testing.B) {
+	svc := newBenchService(b, entrywal.SyncInterval)
 
 	b.ResetTimer()
 	b.RunParallel(func(pb *testing.PB) {
 		for pb.Next() {
 			svc.PlaceOrder(
+				orderbook.DefaultSymbol,
 				orderbook.Bid,
 				orderbook.Limit,
 				100,
@@ -51,3 +71,66 @@ func BenchmarkPlaceOrder_Core(b *testing.B) {
 		}
 	})
 }
+
+// BenchmarkPlaceOrder_Durability reports per-order latency
+// percentiles for each entry WAL sync mode.
+func BenchmarkPlaceOrder_Durability(b *testing.B) {
+	modes := []struct {
+		name string
+		mode entrywal.SyncMode
+	}{
+		{"EveryRecord", entrywal.SyncEveryRecord},
+		{"GroupCommit", entrywal.SyncGroupCommit},
+		{"Interval", entrywal.SyncInterval},
+	}
+
+	for _, m := range modes {
+		b.Run(m.name, func(b *testing.B) {
+			svc := newBenchService(b, m.mode)
+			var (
+				mu  sync.Mutex
+				all []time.Duration
+			)
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				var local []time.Duration
+				for pb.Next() {
+					start := time.Now()
+					svc.PlaceOrder(
+						orderbook.DefaultSymbol,
+						orderbook.Bid,
+						orderbook.Limit,
+						100,
+						1,
+						1,
+					)
+					local = append(local, time.Since(start))
+				}
+				mu.Lock()
+				all = append(all, local...)
+				mu.Unlock()
+			})
+			b.StopTimer()
+
+			reportLatency(b, all)
+		})
+	}
+}
+
+func reportLatency(b *testing.B, lat []time.Duration) {
+	if len(lat) == 0 {
+		return
+	}
+	sort.Slice(lat, func(i, j int) bool { return lat[i] < lat[j] })
+
+	pct := func(p float64) float64 {
+		i := int(p * float64(len(lat)-1))
+		return float64(lat[i].Nanoseconds())
+	}
+
+	b.ReportMetric(pct(0.50), "p50-ns")
+	b.ReportMetric(pct(0.99), "p99-ns")
+	b.ReportMetric(pct(0.999), "p999-ns")
+	b.ReportMetric(float64(lat[len(lat)-1].Nanoseconds()), "max-ns")
+}
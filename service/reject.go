@@ -0,0 +1,62 @@
+package service
+
+import (
+	"errors"
+
+	"loki/domain/orderbook"
+)
+
+// RejectReason maps a domain rejection to the stable code
+// used in outbox events and API responses.
+func RejectReason(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrHalted):
+		return "HALTED"
+	case errors.Is(err, ErrWALUnavailable):
+		return "WAL_UNAVAILABLE"
+	case errors.Is(err, ErrQueueFull):
+		return "QUEUE_FULL"
+	case errors.Is(err, orderbook.ErrFOKNotFillable):
+		return "FOK_NOT_FILLABLE"
+	case errors.Is(err, orderbook.ErrPostOnlyWouldCross):
+		return "POST_ONLY_WOULD_CROSS"
+	case errors.Is(err, orderbook.ErrInvalidSide):
+		return "INVALID_SIDE"
+	case errors.Is(err, orderbook.ErrInvalidType):
+		return "INVALID_TYPE"
+	case errors.Is(err, orderbook.ErrInvalidQty):
+		return "INVALID_QTY"
+	case errors.Is(err, orderbook.ErrInvalidPrice):
+		return "INVALID_PRICE"
+	case errors.Is(err, orderbook.ErrTickSize):
+		return "TICK_SIZE"
+	case errors.Is(err, orderbook.ErrLotSize):
+		return "LOT_SIZE"
+	case errors.Is(err, orderbook.ErrQtyOutOfRange):
+		return "QTY_OUT_OF_RANGE"
+	case errors.Is(err, orderbook.ErrMinNotional):
+		return "MIN_NOTIONAL"
+	case errors.Is(err, orderbook.ErrPriceBand):
+		return "PRICE_BAND"
+	case errors.Is(err, orderbook.ErrInvalidAmend):
+		return "INVALID_AMEND"
+	case errors.Is(err, orderbook.ErrOrderNotFound):
+		return "UNKNOWN_ORDER"
+	case errors.Is(err, orderbook.ErrUnknownSymbol):
+		return "UNKNOWN_SYMBOL"
+	case errors.Is(err, orderbook.ErrInvalidSymbol):
+		return "INVALID_SYMBOL"
+	case errors.Is(err, orderbook.ErrInvalidInstrument):
+		return "INVALID_INSTRUMENT"
+	case errors.Is(err, orderbook.ErrOrderFilled):
+		return "ALREADY_FILLED"
+	case errors.Is(err, ErrDuplicate):
+		return "DUPLICATE"
+	case errors.Is(err, ErrValidation):
+		return "INVALID_REQUEST"
+	default:
+		return "UNKNOWN"
+	}
+}
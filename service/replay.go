@@ -1,89 +1,209 @@
 package service
 
 import (
+	"errors"
 	"fmt"
-	"strconv"
-	"strings"
 
 	"loki/domain/orderbook"
 	"loki/infra/memory"
 	"loki/infra/sequence"
 	entrywal "loki/infra/wal/entry"
+	"loki/snapshot"
 )
 
 /*
-ReplayFromWAL rebuilds in-memory state from Entry WAL.
+Recover rebuilds in-memory state from the newest snapshot plus
+the Entry WAL records after it.
 
 IMPORTANT:
 - This MUST run before accepting traffic
 - Exit WAL is NOT replayed
+- The WAL must continue exactly at snapshot seq + 1. A gap means
+  commands were lost (e.g. WAL truncated behind a snapshot that
+  is gone) and starting anyway would serve a wrong book.
 */
 
-func ReplayFromWAL(
+var ErrRecoveryGap = errors.New("service: entry WAL does not continue from snapshot")
+
+func Recover(
+	snapDir string,
 	walDir string,
-	book *orderbook.OrderBook,
+	books *orderbook.Registry,
 	pool *memory.Pool[orderbook.Order],
 	seqGen *sequence.Sequencer,
 ) error {
-	lastSeq, err := entrywal.Replay(walDir, func(rec *entrywal.Record) error {
-		if rec.Type != entrywal.RecordPlace {
-			return nil
-		}
+	// 1️⃣ Newest snapshot
+	snapSeq, err := snapshot.LoadLatest(snapDir, books, pool)
+	if err != nil {
+		return fmt.Errorf("snapshot load: %w", err)
+	}
 
-		// Payload format:
-		// userID|side|type|price|qty
-		parts := strings.Split(string(rec.Data), "|")
-		if len(parts) != 5 {
-			return fmt.Errorf("invalid WAL payload: %s", string(rec.Data))
+	// 2️⃣ WAL tail, strictly contiguous after the snapshot
+	next := snapSeq + 1
+	lastSeq, err := entrywal.ReplayFrom(walDir, next, func(rec *entrywal.Record) error {
+		if rec.Seq != next {
+			return fmt.Errorf("%w: expected seq %d, found %d", ErrRecoveryGap, next, rec.Seq)
 		}
+		next++
+		return applyRecord(rec, books, pool)
+	})
+	if err != nil {
+		return err
+	}
 
-		// userID is intentionally ignored during replay
-		_, err := strconv.ParseUint(parts[0], 10, 64)
-		if err != nil {
-			return err
-		}
+	// Nothing after the snapshot survived, yet the WAL went
+	// further: those records were truncated behind a newer
+	// snapshot that is gone.
+	if next == snapSeq+1 && lastSeq > snapSeq {
+		return fmt.Errorf("%w: snapshot at seq %d, WAL truncated through seq %d",
+			ErrRecoveryGap, snapSeq, lastSeq)
+	}
 
-		side, err := strconv.Atoi(parts[1])
-		if err != nil {
-			return err
-		}
+	// 3️⃣ Resume sequencing after whichever is further along.
+	// The WAL can end before the snapshot when everything it
+	// held was truncated behind it.
+	if snapSeq > lastSeq {
+		lastSeq = snapSeq
+	}
+	seqGen.Reset(lastSeq)
 
-		otype, err := strconv.Atoi(parts[2])
-		if err != nil {
-			return err
-		}
+	fmt.Printf("recovery completed (snapshot seq = %d, replayed = %d, last seq = %d)\n",
+		snapSeq, next-snapSeq-1, lastSeq)
+	return nil
+}
 
-		price, err := strconv.ParseInt(parts[3], 10, 64)
-		if err != nil {
-			return err
-		}
+func applyRecord(
+	rec *entrywal.Record,
+	books *orderbook.Registry,
+	pool *memory.Pool[orderbook.Order],
+) error {
+	switch rec.Type {
+	case entrywal.RecordPlace:
+		return replayPlace(rec, books, pool)
+	case entrywal.RecordCancel:
+		return replayCancel(rec, books)
+	case entrywal.RecordInstrument:
+		return replayInstrument(rec, books)
+	case entrywal.RecordAmend:
+		return replayAmend(rec, books)
+	default:
+		return nil
+	}
+}
 
-		qty, err := strconv.ParseInt(parts[4], 10, 64)
-		if err != nil {
-			return err
-		}
+// bookFor resolves a journaled symbol. Records written before
+// multi-instrument support carry none and belong to the default book.
+func bookFor(books *orderbook.Registry, symbol string, seq uint64) (*orderbook.OrderBook, error) {
+	if symbol == "" {
+		symbol = orderbook.DefaultSymbol
+	}
+	book := books.Get(symbol)
+	if book == nil {
+		return nil, fmt.Errorf("WAL seq %d: %w %q", seq, orderbook.ErrUnknownSymbol, symbol)
+	}
+	return book, nil
+}
 
-		o := pool.Get()
-		*o = orderbook.Order{
-			ID:     rec.Seq,
-			Side:   orderbook.Side(side),
-			Type:   orderbook.OrderType(otype),
-			Price:  price,
-			Qty:    qty,
-			SeqID:  rec.Seq,
-			Status: orderbook.Active,
-		}
+func replayPlace(
+	rec *entrywal.Record,
+	books *orderbook.Registry,
+	pool *memory.Pool[orderbook.Order],
+) error {
+	cmd, err := entrywal.DecodePlace(rec.Data)
+	if err != nil {
+		return err
+	}
 
-		book.Place(o)
-		return nil
-	})
+	book, err := bookFor(books, cmd.Symbol, rec.Seq)
 	if err != nil {
 		return err
 	}
 
-	// Resume sequencing AFTER replay
-	seqGen.Reset(lastSeq)
+	o := pool.Get()
+	*o = orderbook.Order{
+		ID:     rec.Seq,
+		Side:   orderbook.Side(cmd.Side),
+		Type:   orderbook.OrderType(cmd.Type),
+		Price:  cmd.Price,
+		Qty:    cmd.Qty,
+		SeqID:  rec.Seq,
+		UserID: cmd.UserID,
+		Status: orderbook.Active,
+	}
 
-	fmt.Printf("WAL replay completed successfully (last seq = %d)\n", lastSeq)
+	// Legacy orders were never checked; replay them unchecked
+	// or the rebuilt book drifts from what actually traded.
+	if cmd.Legacy {
+		_, _ = book.PlaceLegacy(o)
+	} else {
+		_, _ = book.Place(o)
+	}
+	return nil
+}
+
+func replayCancel(
+	rec *entrywal.Record,
+	books *orderbook.Registry,
+) error {
+	cmd, err := entrywal.DecodeCancel(rec.Data)
+	if err != nil {
+		return err
+	}
+
+	book, err := bookFor(books, cmd.Symbol, rec.Seq)
+	if err != nil {
+		return err
+	}
+
+	// A cancel that missed live is a no-op on replay too.
+	_, _ = book.Cancel(cmd.OrderID)
+	return nil
+}
+
+func replayAmend(
+	rec *entrywal.Record,
+	books *orderbook.Registry,
+) error {
+	cmd, err := entrywal.DecodeAmend(rec.Data)
+	if err != nil {
+		return err
+	}
+
+	book, err := bookFor(books, cmd.Symbol, rec.Seq)
+	if err != nil {
+		return err
+	}
+
+	// Rejections replay exactly as they happened live.
+	_, _, _ = book.Amend(cmd.OrderID, rec.Seq, cmd.Price, cmd.Qty)
 	return nil
 }
+
+func replayInstrument(
+	rec *entrywal.Record,
+	books *orderbook.Registry,
+) error {
+	cmd, err := entrywal.DecodeInstrument(rec.Data)
+	if err != nil {
+		return err
+	}
+
+	// Already present when it was restored from a snapshot.
+	if books.Get(cmd.Symbol) != nil {
+		return nil
+	}
+
+	_, err = books.Create(orderbook.Instrument{
+		Symbol:   cmd.Symbol,
+		TickSize: cmd.TickSize,
+		PostOnly: orderbook.PostOnlyPolicy(cmd.PostOnly),
+		Rules: orderbook.Rules{
+			LotSize:      cmd.LotSize,
+			MinQty:       cmd.MinQty,
+			MaxQty:       cmd.MaxQty,
+			MinNotional:  cmd.MinNotional,
+			PriceBandBps: cmd.PriceBandBps,
+		},
+	})
+	return err
+}
@@ -0,0 +1,591 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"loki/domain/orderbook"
+	"loki/infra/memory"
+	"loki/infra/sequence"
+	entrywal "loki/infra/wal/entry"
+	exitwal "loki/infra/wal/exit"
+	"loki/snapshot"
+)
+
+type testShard struct {
+	books    *orderbook.Registry
+	seq      *sequence.Sequencer
+	entryWAL *entrywal.WAL
+	exitWAL  *exitwal.ExitWAL
+	svc      *OrderService
+}
+
+// openTestShard recovers shard 0 from dir the way main does.
+func openTestShard(t *testing.T, dir string) (*testShard, error) {
+	t.Helper()
+	return openTestShardAt(t, dir, 0)
+}
+
+func openTestShardAt(t *testing.T, dir string, shard int) (*testShard, error) {
+	t.Helper()
+
+	books := orderbook.NewRegistry()
+	_, _ = books.Create(orderbook.Instrument{Symbol: orderbook.DefaultSymbol, TickSize: 1})
+
+	pool := memory.NewPool(func() *orderbook.Order {
+		return &orderbook.Order{}
+	})
+	seq := sequence.New(0)
+
+	entryDir := filepath.Join(dir, "entry")
+	entryWAL, err := entrywal.Open(entrywal.Config{Dir: entryDir, SegmentSize: 256})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := Recover(filepath.Join(dir, "snapshots"), entryDir, books, pool, seq); err != nil {
+		_ = entryWAL.Close()
+		return nil, err
+	}
+
+	exitWAL, err := exitwal.Open(filepath.Join(dir, "exit"))
+	if err != nil {
+		return nil, err
+	}
+
+	sh := &testShard{books: books, seq: seq, entryWAL: entryWAL, exitWAL: exitWAL}
+	sh.svc = NewOrderService(shard, books, pool, memory.NewRetireRing(64), snapshot.NewReader(), seq, entryWAL, exitWAL)
+	return sh, nil
+}
+
+func (sh *testShard) close() {
+	sh.svc.Close()
+	_ = sh.entryWAL.Close()
+	_ = sh.exitWAL.Close()
+}
+
+// snapshotAndTruncate writes a snapshot at the current seq and
+// drops the WAL behind it, as the snapshot job does.
+func (sh *testShard) snapshotAndTruncate(t *testing.T, dir string) {
+	t.Helper()
+
+	snap, err := sh.svc.CaptureSnapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &snapshot.Writer{Dir: filepath.Join(dir, "snapshots")}
+	if err := w.WriteSnapshot(snap); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sh.entryWAL.TruncateBefore(snap.Seq); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// events returns the pending outbox events of seq, decoded, in
+// Sub order.
+func (sh *testShard) events(t *testing.T, seq uint64) []map[string]any {
+	t.Helper()
+
+	var out []map[string]any
+	err := sh.exitWAL.ScanPending(func(r *exitwal.ExitRecord) error {
+		if r.Seq != seq {
+			return nil
+		}
+		if int(r.Sub) != len(out) {
+			t.Fatalf("seq %d: event sub %d out of order", seq, r.Sub)
+		}
+		var e map[string]any
+		if err := json.Unmarshal(r.Payload, &e); err != nil {
+			return err
+		}
+		out = append(out, e)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func mustPlace(t *testing.T, svc *OrderService, side orderbook.Side, price, qty int64) uint64 {
+	t.Helper()
+	rep, err := svc.PlaceOrder(orderbook.DefaultSymbol, side, orderbook.Limit, price, qty, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rep.OrderID
+}
+
+func TestRecoverSnapshotPlusWALTail(t *testing.T) {
+	dir := t.TempDir()
+
+	sh, err := openTestShard(t, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := int64(1); i <= 8; i++ {
+		mustPlace(t, sh.svc, orderbook.Bid, 100+i, i)
+	}
+	if _, err := sh.svc.CancelOrder(orderbook.DefaultSymbol, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	sh.snapshotAndTruncate(t, dir)
+
+	mustPlace(t, sh.svc, orderbook.Ask, 200, 3)
+	id := mustPlace(t, sh.svc, orderbook.Bid, 90, 4)
+	if _, err := sh.svc.AmendOrder(orderbook.DefaultSymbol, id, 91, 4); err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := sh.svc.Snapshot(orderbook.DefaultSymbol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSeq := sh.seq.Current()
+	sh.close()
+
+	if segs, _ := filepath.Glob(filepath.Join(dir, "entry", "segment-000000.wal")); len(segs) != 0 {
+		t.Fatal("test needs the WAL head truncated")
+	}
+
+	sh, err = openTestShard(t, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sh.close()
+
+	if got := sh.seq.Current(); got != wantSeq {
+		t.Fatalf("sequencer at %d, want %d", got, wantSeq)
+	}
+
+	got, err := sh.svc.Snapshot(orderbook.DefaultSymbol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("recovered %d orders, want %d", len(got), len(want))
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.ID != w.ID || g.Side != w.Side || g.Price != w.Price || g.Qty != w.Qty ||
+			g.Filled != w.Filled || g.SeqID != w.SeqID || g.UserID != w.UserID {
+			t.Fatalf("order %d: got %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+func TestRecoverRefusesGap(t *testing.T) {
+	dir := t.TempDir()
+
+	sh, err := openTestShard(t, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := int64(1); i <= 8; i++ {
+		mustPlace(t, sh.svc, orderbook.Bid, 100, i)
+	}
+	sh.snapshotAndTruncate(t, dir)
+	mustPlace(t, sh.svc, orderbook.Bid, 100, 1)
+	sh.close()
+
+	// the snapshot the truncation relied on is gone
+	if err := os.RemoveAll(filepath.Join(dir, "snapshots")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := openTestShard(t, dir); !errors.Is(err, ErrRecoveryGap) {
+		t.Fatalf("want ErrRecoveryGap, got %v", err)
+	}
+}
+
+func TestRecoverRefusesGapWithoutTail(t *testing.T) {
+	dir := t.TempDir()
+
+	sh, err := openTestShard(t, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// place until the live segment has just rotated, so the
+	// truncation leaves no record behind the snapshot
+	for i := int64(1); ; i++ {
+		if i > 100 {
+			t.Fatal("live segment never rotated")
+		}
+		mustPlace(t, sh.svc, orderbook.Bid, 100, i)
+		sh.snapshotAndTruncate(t, dir)
+		if n, err := sh.entryWAL.RetainedBytes(); err != nil || n == 0 {
+			break
+		}
+	}
+	sh.close()
+
+	if err := os.RemoveAll(filepath.Join(dir, "snapshots")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := openTestShard(t, dir); !errors.Is(err, ErrRecoveryGap) {
+		t.Fatalf("want ErrRecoveryGap, got %v", err)
+	}
+}
+
+func TestCaptureSnapshotMatchesSeq(t *testing.T) {
+	sh, err := openTestShard(t, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sh.close()
+
+	stop := make(chan struct{})
+	placed := make(chan struct{})
+	go func() {
+		defer close(placed)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			// bids never cross, so every order rests
+			_, _ = sh.svc.PlaceOrder(orderbook.DefaultSymbol, orderbook.Bid, orderbook.Limit, 100, 1, 1)
+		}
+	}()
+
+	for i := 0; i < 20; i++ {
+		snap, err := sh.svc.CaptureSnapshot()
+		if err != nil {
+			continue
+		}
+		var n uint64
+		for _, lvl := range snap.Levels {
+			for _, o := range lvl.Orders {
+				if o.ID > snap.Seq {
+					t.Fatalf("snapshot at seq %d holds order %d", snap.Seq, o.ID)
+				}
+				n++
+			}
+		}
+		if n != snap.Seq {
+			t.Fatalf("snapshot at seq %d holds %d orders", snap.Seq, n)
+		}
+	}
+
+	close(stop)
+	<-placed
+}
+
+func TestSnapshotJobTruncatesBehindAckedEvents(t *testing.T) {
+	dir := t.TempDir()
+
+	sh, err := openTestShard(t, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sh.close()
+
+	for i := int64(1); i <= 20; i++ {
+		mustPlace(t, sh.svc, orderbook.Bid, 100, i)
+	}
+
+	sh.svc.StartSnapshotJob(SnapshotJobConfig{
+		Dir:      filepath.Join(dir, "snapshots"),
+		Interval: time.Millisecond,
+	})
+
+	waitFor(t, func() bool { return sh.svc.SnapshotMetrics().LastSeq == 20 })
+
+	// every outbox event is still pending, so the WAL stays
+	time.Sleep(10 * time.Millisecond)
+	if m := sh.svc.SnapshotMetrics(); m.TruncatedSeq != 0 {
+		t.Fatalf("truncated to %d with events pending", m.TruncatedSeq)
+	}
+
+	_ = sh.exitWAL.ScanPending(func(rec *exitwal.ExitRecord) error {
+		return sh.exitWAL.MarkAcked(rec.Seq, rec.Sub)
+	})
+
+	waitFor(t, func() bool { return sh.svc.SnapshotMetrics().TruncatedSeq == 20 })
+	m := sh.svc.SnapshotMetrics()
+	retained, _ := sh.entryWAL.RetainedBytes()
+	if m.ReclaimedBytes <= 0 || m.RetainedBytes != retained {
+		t.Fatalf("metrics: %+v", m)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWALFailureHalts(t *testing.T) {
+	sh, err := openTestShard(t, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sh.close()
+
+	id := mustPlace(t, sh.svc, orderbook.Bid, 100, 1)
+
+	// every append fails from here on
+	_ = sh.entryWAL.Close()
+
+	_, err = sh.svc.PlaceOrder(orderbook.DefaultSymbol, orderbook.Bid, orderbook.Limit, 100, 1, 1)
+	if !errors.Is(err, ErrWALUnavailable) {
+		t.Fatalf("want ErrWALUnavailable, got %v", err)
+	}
+	if sh.svc.Halted() == nil {
+		t.Fatal("service did not halt")
+	}
+
+	if _, err := sh.svc.CancelOrder(orderbook.DefaultSymbol, id); !errors.Is(err, ErrHalted) {
+		t.Fatalf("want ErrHalted, got %v", err)
+	}
+	if _, ok, err := sh.svc.GetOrder(orderbook.DefaultSymbol, id); err != nil || !ok {
+		t.Fatal("reads must keep working while halted")
+	}
+}
+
+func TestServiceErrorKinds(t *testing.T) {
+	sh, err := openTestShard(t, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sh.close()
+
+	_, err = sh.svc.CancelOrder(orderbook.DefaultSymbol, 42)
+	if !errors.Is(err, ErrUnknownOrder) || !errors.Is(err, orderbook.ErrOrderNotFound) {
+		t.Fatalf("cancel unknown: %v", err)
+	}
+
+	_, err = sh.svc.CreateInstrument(orderbook.Instrument{Symbol: orderbook.DefaultSymbol, TickSize: 1})
+	if !errors.Is(err, ErrDuplicate) {
+		t.Fatalf("duplicate instrument: %v", err)
+	}
+
+	long := strings.Repeat("X", orderbook.MaxSymbolLen+1)
+	_, err = sh.svc.CreateInstrument(orderbook.Instrument{Symbol: long, TickSize: 1})
+	if !errors.Is(err, ErrValidation) || !errors.Is(err, orderbook.ErrInvalidSymbol) {
+		t.Fatalf("symbol too long to journal: %v", err)
+	}
+
+	_, err = sh.svc.PlaceOrder("NOPE", orderbook.Bid, orderbook.Limit, 100, 1, 1)
+	if !errors.Is(err, ErrValidation) || RejectReason(err) != "UNKNOWN_SYMBOL" {
+		t.Fatalf("unknown symbol: %v", err)
+	}
+
+	maker := mustPlace(t, sh.svc, orderbook.Ask, 100, 1)
+	mustPlace(t, sh.svc, orderbook.Bid, 100, 1)
+	_, err = sh.svc.CancelOrder(orderbook.DefaultSymbol, maker)
+	if !errors.Is(err, orderbook.ErrOrderFilled) || RejectReason(err) != "ALREADY_FILLED" {
+		t.Fatalf("cancel filled: %v", err)
+	}
+}
+
+func TestOrderRulesRejectAndRecover(t *testing.T) {
+	dir := t.TempDir()
+
+	sh, err := openTestShard(t, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inst := orderbook.Instrument{
+		Symbol:   "ETH-USD",
+		TickSize: 5,
+		Rules:    orderbook.Rules{LotSize: 2, MinQty: 2, MaxQty: 100, MinNotional: 500, PriceBandBps: 1000},
+	}
+	if _, err := sh.svc.CreateInstrument(inst); err != nil {
+		t.Fatal(err)
+	}
+	bad := inst
+	bad.Symbol, bad.Rules.MinQty = "BAD-USD", 1000
+	if _, err := sh.svc.CreateInstrument(bad); !errors.Is(err, ErrValidation) {
+		t.Fatalf("inconsistent rules: %v", err)
+	}
+
+	rep, err := sh.svc.PlaceOrder("ETH-USD", orderbook.Bid, orderbook.Limit, 101, 10, 1)
+	if !errors.Is(err, ErrValidation) || RejectReason(err) != "TICK_SIZE" {
+		t.Fatalf("off-tick price: %v", err)
+	}
+	if rep.Status != ExecRejected || rep.Seq == 0 {
+		t.Fatalf("off-tick report: %+v", rep)
+	}
+
+	// The rejection is sequenced, so it has an outbox event.
+	if ev := sh.events(t, rep.Seq); len(ev) != 1 || ev[0]["type"] != "ORDER_REJECTED" || ev[0]["reason"] != "TICK_SIZE" {
+		t.Fatalf("rejection events: %v", ev)
+	}
+
+	// Trade at 100, so 115 is out of the 10% band.
+	for _, side := range []orderbook.Side{orderbook.Bid, orderbook.Ask} {
+		if _, err := sh.svc.PlaceOrder("ETH-USD", side, orderbook.Limit, 100, 10, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := sh.svc.PlaceOrder("ETH-USD", orderbook.Ask, orderbook.Limit, 115, 10, 1); RejectReason(err) != "PRICE_BAND" {
+		t.Fatalf("outside band: %v", err)
+	}
+	sh.close()
+
+	// Replay rebuilds the rules and the band reference.
+	sh, err = openTestShard(t, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sh.close()
+
+	if got := sh.books.Get("ETH-USD").Instrument(); got != inst {
+		t.Fatalf("recovered instrument %+v, want %+v", got, inst)
+	}
+	if _, err := sh.svc.PlaceOrder("ETH-USD", orderbook.Ask, orderbook.Limit, 115, 10, 1); RejectReason(err) != "PRICE_BAND" {
+		t.Fatalf("outside band after recovery: %v", err)
+	}
+}
+
+func TestRecoverReplaysLegacyUnchecked(t *testing.T) {
+	dir := t.TempDir()
+
+	// the first release journaled a MARKET bid with a price, which
+	// today's rules reject, and matched it against the ask
+	w, err := entrywal.Open(entrywal.Config{Dir: filepath.Join(dir, "entry")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, payload := range []string{"1|1|0|100|5", "2|0|1|100|5"} {
+		if err := w.Append(entrywal.NewRecord(entrywal.RecordPlace, uint64(i+1), []byte(payload))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = w.Close()
+
+	sh, err := openTestShard(t, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sh.close()
+
+	if o, ok, _ := sh.svc.GetOrder(orderbook.DefaultSymbol, 1); ok {
+		t.Fatalf("legacy ask still resting after replay: %+v", o)
+	}
+}
+
+func TestQueriesReportQueueFull(t *testing.T) {
+	sh, err := openTestShard(t, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sh.close()
+
+	// park the writer, then fill the inbox behind it
+	started, release := make(chan struct{}), make(chan struct{})
+	go func() {
+		_ = sh.svc.query(func() {
+			close(started)
+			<-release
+		})
+	}()
+	<-started
+	for len(sh.svc.inbox) < cap(sh.svc.inbox) {
+		sh.svc.inbox <- &command{kind: cmdQuery, fn: func() {}, done: make(chan struct{})}
+	}
+
+	if _, _, err := sh.svc.GetOrder(orderbook.DefaultSymbol, 1); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("GetOrder on a full queue: %v", err)
+	}
+	if _, err := sh.svc.Snapshot(orderbook.DefaultSymbol); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Snapshot on a full queue: %v", err)
+	}
+	close(release)
+}
+
+func TestPostOnlySlideReport(t *testing.T) {
+	sh, err := openTestShard(t, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sh.close()
+
+	inst := orderbook.Instrument{Symbol: "SLIDE-USD", TickSize: 5, PostOnly: orderbook.PostOnlySlide}
+	if _, err := sh.svc.CreateInstrument(inst); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sh.svc.PlaceOrder("SLIDE-USD", orderbook.Ask, orderbook.Limit, 105, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	rep, err := sh.svc.PlaceOrder("SLIDE-USD", orderbook.Bid, orderbook.PostOnly, 110, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Status != ExecResting || !rep.Repriced || rep.Price != 100 {
+		t.Fatalf("slid report: %+v", rep)
+	}
+	ev := sh.events(t, rep.Seq)
+	if len(ev) != 1 || ev[0]["repriced"] != true || ev[0]["requested_price"] != float64(110) || ev[0]["price"] != float64(100) {
+		t.Fatalf("slid event: %v", ev)
+	}
+
+	// Not slid: no repricing fields.
+	rep, err = sh.svc.PlaceOrder("SLIDE-USD", orderbook.Bid, orderbook.PostOnly, 95, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev := sh.events(t, rep.Seq); rep.Repriced || ev[0]["repriced"] != nil {
+		t.Fatalf("unslid order repriced: %+v %v", rep, ev)
+	}
+}
+
+func TestTradeEventsPerFill(t *testing.T) {
+	sh, err := openTestShard(t, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sh.close()
+
+	m1 := mustPlace(t, sh.svc, orderbook.Ask, 101, 2)
+	m2 := mustPlace(t, sh.svc, orderbook.Ask, 101, 3)
+	m3 := mustPlace(t, sh.svc, orderbook.Ask, 102, 4)
+
+	rep, err := sh.svc.PlaceOrder(orderbook.DefaultSymbol, orderbook.Bid, orderbook.Limit, 102, 7, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ev := sh.events(t, rep.Seq)
+	if len(ev) != 4 || ev[0]["type"] != "ORDER_ACCEPTED" {
+		t.Fatalf("events: %v", ev)
+	}
+
+	want := []struct {
+		maker      uint64
+		price, qty int64
+	}{
+		{m1, 101, 2},
+		{m2, 101, 3},
+		{m3, 102, 2},
+	}
+	for i, w := range want {
+		e := ev[1+i]
+		if e["type"] != "TRADE" ||
+			e["maker_id"] != float64(w.maker) ||
+			e["taker_id"] != float64(rep.OrderID) ||
+			e["price"] != float64(w.price) ||
+			e["qty"] != float64(w.qty) ||
+			e["aggressor"] != float64(orderbook.Bid) ||
+			e["seq"] != float64(rep.Seq) {
+			t.Fatalf("trade %d: %v, want maker %d %d@%d", i, e, w.maker, w.qty, w.price)
+		}
+	}
+}
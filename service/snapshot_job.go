@@ -1,34 +1,176 @@
 package service
 
 import (
+	"fmt"
+	"sync/atomic"
 	"time"
 
 	"loki/snapshot"
 )
 
-func (s *OrderService) StartSnapshotJob(
-	dir string,
-	interval time.Duration,
-) {
-	w := &snapshot.Writer{Dir: dir}
+type SnapshotJobConfig struct {
+	Dir      string
+	Interval time.Duration
 
+	// Retain and Compress are passed to snapshot.Writer.
+	Retain   int
+	Compress bool
+
+	// TruncateLag keeps this many seqs of entry WAL behind the
+	// snapshot, so a replica or audit reader trailing slightly
+	// has room, and a bad newest snapshot can fall back a step.
+	TruncateLag uint64
+}
+
+// SnapshotMetrics are cumulative since the service started,
+// except for the gauges noted.
+type SnapshotMetrics struct {
+	Snapshots      int64
+	VerifyFailures int64
+	LastSeq        uint64 // gauge: newest verified snapshot
+	TruncatedSeq   uint64 // gauge: entry WAL truncated up to here
+	ReclaimedBytes int64
+	RetainedBytes  int64 // gauge: entry WAL still on disk
+}
+
+type snapshotMetrics struct {
+	snapshots      atomic.Int64
+	verifyFailures atomic.Int64
+	lastSeq        atomic.Uint64
+	truncatedSeq   atomic.Uint64
+	reclaimedBytes atomic.Int64
+	retainedBytes  atomic.Int64
+}
+
+func (s *OrderService) SnapshotMetrics() SnapshotMetrics {
+	m := &s.snapMetrics
+	return SnapshotMetrics{
+		Snapshots:      m.snapshots.Load(),
+		VerifyFailures: m.verifyFailures.Load(),
+		LastSeq:        m.lastSeq.Load(),
+		TruncatedSeq:   m.truncatedSeq.Load(),
+		ReclaimedBytes: m.reclaimedBytes.Load(),
+		RetainedBytes:  m.retainedBytes.Load(),
+	}
+}
+
+// StartSnapshotJob periodically snapshots every book and then
+// truncates both WALs behind it. It stops when the service is
+// closed.
+func (s *OrderService) StartSnapshotJob(cfg SnapshotJobConfig) {
+	w := &snapshot.Writer{
+		Dir:      cfg.Dir,
+		Retain:   cfg.Retain,
+		Compress: cfg.Compress,
+	}
+
+	s.jobs.Add(1)
 	go func() {
-		t := time.NewTicker(interval)
+		defer s.jobs.Done()
+
+		t := time.NewTicker(cfg.Interval)
 		defer t.Stop()
 
-		for range t.C {
-			seq := s.seqGen.Current()
+		var verified bool
+		var last uint64
+
+		for {
+			select {
+			case <-s.quit:
+				return
+			case <-t.C:
+			}
 
-			// Write snapshot
-			if err := w.Write(seq, s.book); err != nil {
+			// Cut on the writer, encode and write off it
+			snap, err := s.CaptureSnapshot()
+			if err != nil {
 				continue
 			}
 
-			// Truncate ENTRY WAL after snapshot
-			_ = s.entryWAL.TruncateBefore(seq)
+			// Nothing applied since the last one: no new file, but
+			// outbox acks may have freed more WAL meanwhile
+			if !verified || snap.Seq != last {
+				verified = false
+				if err := w.WriteSnapshot(snap); err != nil {
+					continue
+				}
+				s.snapMetrics.snapshots.Add(1)
 
-			// GC EXIT WAL (acked only)
-			_ = s.exitWAL.TruncateAckedUpTo(seq)
+				// Only what a verified snapshot covers may go
+				if err := s.verifySnapshot(w, snap); err != nil {
+					s.snapMetrics.verifyFailures.Add(1)
+					continue
+				}
+				verified, last = true, snap.Seq
+				s.snapMetrics.lastSeq.Store(last)
+			}
+
+			s.truncate(last, cfg.TruncateLag)
 		}
 	}()
 }
+
+// verifySnapshot re-reads what was just written, which checks
+// the footer checksum, and confirms it is the snapshot we cut
+// and that the entry WAL really holds everything up to it.
+func (s *OrderService) verifySnapshot(w *snapshot.Writer, snap *snapshot.Snapshot) error {
+	got, err := snapshot.Read(w.Path(snap.Seq))
+	if err != nil {
+		return err
+	}
+	if got.Seq != snap.Seq || len(got.Levels) != len(snap.Levels) {
+		return fmt.Errorf("snapshot %d read back as seq %d with %d levels, want %d",
+			snap.Seq, got.Seq, len(got.Levels), len(snap.Levels))
+	}
+	if walSeq := s.entryWAL.LastSeq(); walSeq < snap.Seq {
+		return fmt.Errorf("snapshot %d is ahead of entry WAL at %d", snap.Seq, walSeq)
+	}
+	return nil
+}
+
+// truncate drops entry WAL up to seq less the lag, but never
+// records whose outbox events are still waiting to go out, and
+// acked exit events up to the same point.
+func (s *OrderService) truncate(seq, lag uint64) {
+	if seq <= lag {
+		return
+	}
+	upTo := seq - lag
+
+	pending, ok, err := s.exitWAL.LowestPending()
+	if err != nil {
+		return
+	}
+	if ok && pending <= upTo {
+		upTo = pending - 1
+	}
+	if upTo == 0 || upTo <= s.snapMetrics.truncatedSeq.Load() {
+		return
+	}
+
+	// Truncate ENTRY WAL after snapshot
+	reclaimed, err := s.entryWAL.TruncateBefore(upTo)
+	if err != nil {
+		return
+	}
+	s.snapMetrics.truncatedSeq.Store(upTo)
+	s.snapMetrics.reclaimedBytes.Add(reclaimed)
+
+	if retained, err := s.entryWAL.RetainedBytes(); err == nil {
+		s.snapMetrics.retainedBytes.Store(retained)
+	}
+
+	// GC EXIT WAL (acked only)
+	_ = s.exitWAL.TruncateAckedUpTo(upTo)
+}
+
+// CaptureSnapshot copies all books on the writer goroutine,
+// between batches' commands, so Snapshot.Seq is exactly the last
+// command applied. The writer is paused for the copy only.
+func (s *OrderService) CaptureSnapshot() (*snapshot.Snapshot, error) {
+	var snap *snapshot.Snapshot
+	err := s.query(func() {
+		snap = snapshot.Capture(s.applied, s.books)
+	})
+	return snap, err
+}
@@ -0,0 +1,380 @@
+package service
+
+import (
+	"fmt"
+
+	"loki/domain/orderbook"
+	entrywal "loki/infra/wal/entry"
+)
+
+type cmdKind uint8
+
+const (
+	cmdPlace cmdKind = iota
+	cmdCancel
+	cmdInstrument
+	cmdAmend
+	cmdQuery
+)
+
+// command is one unit of work for the writer goroutine. Inputs
+// are set by the caller; outputs are set by the writer before
+// done is closed.
+type command struct {
+	kind cmdKind
+
+	// inputs
+	symbol  string
+	side    orderbook.Side
+	otype   orderbook.OrderType
+	price   int64
+	qty     int64
+	userID  uint64
+	orderID uint64
+	inst    orderbook.Instrument
+	fn      func()
+
+	// outputs
+	seq    uint64
+	report ExecutionReport
+	err    error
+
+	// rec is the journal record, nil for commands that were
+	// refused before sequencing or are read-only.
+	rec  *entrywal.Record
+	done chan struct{}
+}
+
+// submit enqueues c without blocking and waits for the writer.
+// Writes are refused up front once the service has halted.
+func (s *OrderService) submit(c *command) error {
+	if c.kind != cmdQuery && s.halted.Load() {
+		return &Error{Kind: ErrHalted, Err: s.haltErr}
+	}
+	c.done = make(chan struct{})
+
+	select {
+	case s.inbox <- c:
+	default:
+		return ErrQueueFull
+	}
+
+	<-c.done
+	return nil
+}
+
+// query runs fn on the writer goroutine, between commands.
+func (s *OrderService) query(fn func()) error {
+	return s.submit(&command{kind: cmdQuery, fn: fn})
+}
+
+// -------------------- WRITER LOOP --------------------
+
+func (s *OrderService) run() {
+	defer close(s.done)
+
+	batch := make([]*command, 0, maxBatch)
+
+	for c := range s.inbox {
+		batch = append(batch[:0], c)
+
+	drain:
+		for len(batch) < maxBatch {
+			select {
+			case c, ok := <-s.inbox:
+				if !ok {
+					break drain
+				}
+				batch = append(batch, c)
+			default:
+				break drain
+			}
+		}
+
+		s.apply(batch)
+	}
+}
+
+// apply runs one batch through the strict pipeline. The whole
+// batch is journaled before any of it executes, so the WAL
+// order is exactly the execution order.
+func (s *OrderService) apply(batch []*command) {
+	// 1️⃣ + 2️⃣ Sequence and persist intent (ENTRY WAL)
+	s.journal(batch)
+
+	// 3️⃣ + 4️⃣ Execute in seq order and emit outbox events
+	for _, c := range batch {
+		switch {
+		case c.kind == cmdQuery:
+			c.fn()
+		case c.rec == nil:
+			// refused before sequencing, c.err is set
+		case c.kind == cmdPlace:
+			s.execPlace(c)
+		case c.kind == cmdCancel:
+			s.execCancel(c)
+		case c.kind == cmdInstrument:
+			s.execInstrument(c)
+		case c.kind == cmdAmend:
+			s.execAmend(c)
+		}
+		if c.rec != nil {
+			s.applied = c.seq
+		}
+	}
+
+	// 5️⃣ Respond to clients
+	for _, c := range batch {
+		close(c.done)
+	}
+}
+
+// journal assigns sequence numbers and appends one record per
+// accepted command. Commands that can be refused without
+// touching state never consume a seq. The whole batch goes to
+// the WAL as one write, so it costs at most one fsync.
+func (s *OrderService) journal(batch []*command) {
+	// Queued before the halt, refused all the same
+	if s.halted.Load() {
+		for _, c := range batch {
+			if c.kind != cmdQuery {
+				c.report = ExecutionReport{Status: ExecRejected}
+				c.err = &Error{Kind: ErrHalted, Err: s.haltErr}
+			}
+		}
+		return
+	}
+
+	var recs []*entrywal.Record
+
+	// Symbols created earlier in this batch are not in the
+	// registry yet, but will be by the time later commands run.
+	var created map[string]bool
+	known := func(symbol string) bool {
+		return s.books.Get(symbol) != nil || created[symbol]
+	}
+
+	for _, c := range batch {
+		var (
+			typ  entrywal.RecordType
+			data []byte
+			err  error
+		)
+
+		switch c.kind {
+		case cmdPlace:
+			if !known(c.symbol) {
+				c.report = ExecutionReport{Status: ExecRejected}
+				c.err = orderbook.ErrUnknownSymbol
+				continue
+			}
+			typ = entrywal.RecordPlace
+			data, err = entrywal.EncodePlace(&entrywal.PlaceCommand{
+				Symbol: c.symbol,
+				UserID: c.userID,
+				Side:   uint8(c.side),
+				Type:   uint8(c.otype),
+				Price:  c.price,
+				Qty:    c.qty,
+			})
+
+		case cmdCancel:
+			if !known(c.symbol) {
+				c.err = orderbook.ErrUnknownSymbol
+				continue
+			}
+			typ = entrywal.RecordCancel
+			data, err = entrywal.EncodeCancel(&entrywal.CancelCommand{
+				Symbol:  c.symbol,
+				OrderID: c.orderID,
+			})
+
+		case cmdAmend:
+			if !known(c.symbol) {
+				c.report = ExecutionReport{Status: ExecRejected}
+				c.err = orderbook.ErrUnknownSymbol
+				continue
+			}
+			typ = entrywal.RecordAmend
+			data, err = entrywal.EncodeAmend(&entrywal.AmendCommand{
+				Symbol:  c.symbol,
+				OrderID: c.orderID,
+				Price:   c.price,
+				Qty:     c.qty,
+			})
+
+		case cmdInstrument:
+			if err := c.inst.Validate(); err != nil {
+				c.err = err
+				continue
+			}
+			if known(c.symbol) {
+				c.err = orderbook.ErrInstrumentExists
+				continue
+			}
+			typ = entrywal.RecordInstrument
+			data, err = entrywal.EncodeInstrument(&entrywal.InstrumentCommand{
+				Symbol:   c.inst.Symbol,
+				TickSize: c.inst.TickSize,
+				PostOnly: uint8(c.inst.PostOnly),
+
+				LotSize:      c.inst.Rules.LotSize,
+				MinQty:       c.inst.Rules.MinQty,
+				MaxQty:       c.inst.Rules.MaxQty,
+				MinNotional:  c.inst.Rules.MinNotional,
+				PriceBandBps: c.inst.Rules.PriceBandBps,
+			})
+			if err == nil {
+				if created == nil {
+					created = make(map[string]bool)
+				}
+				created[c.symbol] = true
+			}
+
+		default:
+			continue
+		}
+
+		if err != nil {
+			c.err = err
+			continue
+		}
+
+		c.seq = s.seqGen.Next()
+		c.rec = entrywal.NewRecord(typ, c.seq, data)
+		recs = append(recs, c.rec)
+	}
+
+	if len(recs) == 0 {
+		return
+	}
+	if err := s.entryWAL.AppendBatch(recs); err != nil {
+		s.halt(err)
+
+		// Nothing executes; the batch may or may not have
+		// reached disk, recovery decides on restart.
+		for _, c := range batch {
+			if c.rec == nil {
+				continue
+			}
+			c.rec, c.seq = nil, 0
+			c.report = ExecutionReport{Status: ExecRejected}
+			c.err = &Error{Kind: ErrWALUnavailable, Err: err}
+		}
+	}
+}
+
+// halt puts the service into read-only mode for good. Called on
+// the writer goroutine only.
+func (s *OrderService) halt(err error) {
+	s.haltErr = fmt.Errorf("entry WAL append failed: %w", err)
+	s.halted.Store(true)
+	fmt.Printf("[ERROR] %v: halting, writes refused until restart\n", s.haltErr)
+}
+
+// -------------------- EXECUTION --------------------
+
+func (s *OrderService) execPlace(c *command) {
+	book := s.books.Get(c.symbol)
+
+	o := s.pool.Get()
+	*o = orderbook.Order{
+		ID:     c.seq,
+		Side:   c.side,
+		Type:   c.otype,
+		Price:  c.price,
+		Qty:    c.qty,
+		SeqID:  c.seq,
+		UserID: c.userID,
+		Status: orderbook.Active,
+	}
+
+	trades, err := book.Place(o)
+	if err != nil {
+		// Emit rejection (EXIT WAL), book is untouched
+		payload := s.buildOrderRejectedPayload(c.symbol, o, err)
+		s.emit(c.seq, payload)
+		s.retire(o)
+
+		c.report = ExecutionReport{
+			Seq:          c.seq,
+			OrderID:      o.ID,
+			Status:       ExecRejected,
+			Price:        c.price,
+			RemainingQty: c.qty,
+		}
+		c.err = err
+		return
+	}
+
+	events := make([][]byte, 0, 1+len(trades))
+	events = append(events, s.buildOrderAcceptedPayload(c.symbol, o, c.price))
+	for i := range trades {
+		events = append(events, s.buildTradePayload(c.symbol, &trades[i]))
+	}
+	s.emit(c.seq, events...)
+
+	c.report = newExecutionReport(o, c.price, trades, book.Get(o.ID) == o)
+
+	// Retire immediately if nothing rests
+	if c.report.Status == ExecFilled || c.report.Status == ExecCanceled {
+		s.retire(o)
+	}
+}
+
+func (s *OrderService) execCancel(c *command) {
+	book := s.books.Get(c.symbol)
+
+	// Unlink from its price level
+	o, err := book.Cancel(c.orderID)
+	if err != nil {
+		c.err = err
+		return
+	}
+
+	s.emit(c.seq, s.buildOrderCanceledPayload(c.symbol, c.seq, o))
+	s.retire(o)
+}
+
+func (s *OrderService) execAmend(c *command) {
+	book := s.books.Get(c.symbol)
+
+	o, trades, err := book.Amend(c.orderID, c.seq, c.price, c.qty)
+	if err != nil {
+		// Book untouched, the order keeps resting as it was
+		c.report = ExecutionReport{Seq: c.seq, OrderID: c.orderID, Status: ExecRejected}
+		c.err = err
+		return
+	}
+
+	events := make([][]byte, 0, 1+len(trades))
+	events = append(events, s.buildOrderAmendedPayload(c.symbol, c.seq, o))
+	for i := range trades {
+		events = append(events, s.buildTradePayload(c.symbol, &trades[i]))
+	}
+	s.emit(c.seq, events...)
+
+	c.report = newExecutionReport(o, c.price, trades, book.Get(o.ID) == o)
+	c.report.Seq = c.seq
+
+	if c.report.Status == ExecFilled || c.report.Status == ExecCanceled {
+		s.retire(o)
+	}
+}
+
+func (s *OrderService) execInstrument(c *command) {
+	if _, err := s.books.Create(c.inst); err != nil {
+		c.err = err
+		return
+	}
+
+	s.emit(c.seq, s.buildInstrumentCreatedPayload(c.seq, c.inst))
+}
+
+// emit writes all outbox events of one command atomically.
+func (s *OrderService) emit(seq uint64, events ...[]byte) {
+	if err := s.exitWAL.PutNewBatch(seq, events); err != nil {
+		// Non-blocking: broadcaster will retry
+		fmt.Printf("[WARN] exit WAL write failed for seq %d: %v\n", seq, err)
+	}
+}
@@ -0,0 +1,289 @@
+package snapshot
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+/*
+Binary snapshot format.
+
+	header (never compressed):
+	  [magic:4 "LKSB"][ver:1][flags:1]
+	  [seq:8][created unix ns:8]
+	  [instruments:4][levels:4][orders:8]
+
+	then, zstd-compressed if flags&flagZstd:
+	  instruments: [symLen:1][sym][tick:8][postOnly:1]
+	               v2: + [lot:8][minQty:8][maxQty:8][minNotional:8]
+	                     [bandBps:8][lastPrice:8]
+	  levels:      [blockLen:4][block]
+	    block = [symLen:1][sym][side:1][price:8][count:4]
+	            count × [id:8][type:1][qty:8][filled:8][seqID:8][userID:8]
+
+All integers are big-endian. Levels appear in the order Capture
+walks them, so every block is one queue in FIFO order. Blocks
+are length-prefixed so a reader can skip what it does not need.
+v1 files, written before instrument rules, are still read.
+*/
+
+const (
+	binaryMagic = "LKSB"
+	binaryV1    = 1
+	binaryV2    = 2
+
+	flagZstd byte = 1 << 0
+
+	headerSize = 4 + 1 + 1 + 8 + 8 + 4 + 4 + 8
+	orderSize  = 8 + 1 + 8 + 8 + 8 + 8
+	rulesSize  = 6 * 8
+)
+
+var (
+	ErrUnknownVersion = errors.New("snapshot: unknown format version")
+	ErrSymbolTooLong  = errors.New("snapshot: symbol longer than 255 bytes")
+	ErrCorrupt        = errors.New("snapshot: malformed body")
+)
+
+// -------------------- ENCODE --------------------
+
+// encodeBinary streams s to w.
+func encodeBinary(w io.Writer, s *Snapshot, compress bool) error {
+	var orders uint64
+	for _, lvl := range s.Levels {
+		orders += uint64(len(lvl.Orders))
+	}
+
+	h := make([]byte, 0, headerSize)
+	h = append(h, binaryMagic...)
+	h = append(h, binaryV2, 0)
+	if compress {
+		h[5] |= flagZstd
+	}
+	h = binary.BigEndian.AppendUint64(h, s.Seq)
+	h = binary.BigEndian.AppendUint64(h, uint64(s.Created.UnixNano()))
+	h = binary.BigEndian.AppendUint32(h, uint32(len(s.Instruments)))
+	h = binary.BigEndian.AppendUint32(h, uint32(len(s.Levels)))
+	h = binary.BigEndian.AppendUint64(h, orders)
+	if _, err := w.Write(h); err != nil {
+		return err
+	}
+
+	out := w
+	var zw *zstd.Encoder
+	if compress {
+		var err error
+		if zw, err = zstd.NewWriter(w, zstd.WithEncoderConcurrency(1)); err != nil {
+			return err
+		}
+		out = zw
+	}
+	bw := bufio.NewWriterSize(out, 64<<10)
+
+	var b []byte
+	for _, e := range s.Instruments {
+		var err error
+		if b, err = putSymbol(b[:0], e.Symbol); err != nil {
+			return err
+		}
+		b = binary.BigEndian.AppendUint64(b, uint64(e.TickSize))
+		b = append(b, byte(e.PostOnly))
+		for _, v := range []int64{e.LotSize, e.MinQty, e.MaxQty, e.MinNotional, e.PriceBandBps, e.LastPrice} {
+			b = binary.BigEndian.AppendUint64(b, uint64(v))
+		}
+		if _, err := bw.Write(b); err != nil {
+			return err
+		}
+	}
+
+	for _, lvl := range s.Levels {
+		var err error
+		// room for the block length, patched below
+		if b, err = putSymbol(append(b[:0], 0, 0, 0, 0), lvl.Symbol); err != nil {
+			return err
+		}
+		b = append(b, byte(lvl.Side))
+		b = binary.BigEndian.AppendUint64(b, uint64(lvl.Price))
+		b = binary.BigEndian.AppendUint32(b, uint32(len(lvl.Orders)))
+		for _, o := range lvl.Orders {
+			b = binary.BigEndian.AppendUint64(b, o.ID)
+			b = append(b, byte(o.Type))
+			b = binary.BigEndian.AppendUint64(b, uint64(o.Qty))
+			b = binary.BigEndian.AppendUint64(b, uint64(o.Filled))
+			b = binary.BigEndian.AppendUint64(b, o.SeqID)
+			b = binary.BigEndian.AppendUint64(b, o.UserID)
+		}
+		binary.BigEndian.PutUint32(b[0:4], uint32(len(b)-4))
+		if _, err := bw.Write(b); err != nil {
+			return err
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	if zw != nil {
+		return zw.Close()
+	}
+	return nil
+}
+
+func putSymbol(b []byte, symbol string) ([]byte, error) {
+	if len(symbol) > 255 {
+		return nil, ErrSymbolTooLong
+	}
+	b = append(b, byte(len(symbol)))
+	return append(b, symbol...), nil
+}
+
+// -------------------- DECODE --------------------
+
+// isBinary reports whether a body starts with the binary header.
+func isBinary(prefix []byte) bool {
+	return len(prefix) >= 4 && string(prefix[:4]) == binaryMagic
+}
+
+// decodeBinary reads a snapshot written by encodeBinary.
+func decodeBinary(r io.Reader) (*Snapshot, error) {
+	h := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, h); err != nil {
+		return nil, err
+	}
+	if !isBinary(h) {
+		return nil, ErrCorrupt
+	}
+	ver := h[4]
+	if ver != binaryV1 && ver != binaryV2 {
+		return nil, fmt.Errorf("%w %d", ErrUnknownVersion, ver)
+	}
+	flags := h[5]
+
+	s := &Snapshot{
+		Seq:     binary.BigEndian.Uint64(h[6:14]),
+		Created: time.Unix(0, int64(binary.BigEndian.Uint64(h[14:22]))),
+	}
+	nInst := binary.BigEndian.Uint32(h[22:26])
+	nLevels := binary.BigEndian.Uint32(h[26:30])
+	nOrders := binary.BigEndian.Uint64(h[30:38])
+
+	if flags&flagZstd != 0 {
+		zr, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		r = zr
+	}
+	br := bufio.NewReaderSize(r, 64<<10)
+
+	s.Instruments = make([]InstrumentEntry, 0, nInst)
+	for range nInst {
+		symbol, err := readSymbol(br)
+		if err != nil {
+			return nil, err
+		}
+		var buf [9 + rulesSize]byte
+		rest := buf[:9]
+		if ver >= binaryV2 {
+			rest = buf[:]
+		}
+		if _, err := io.ReadFull(br, rest); err != nil {
+			return nil, err
+		}
+		e := InstrumentEntry{
+			Symbol:   symbol,
+			TickSize: int64(binary.BigEndian.Uint64(rest[0:8])),
+			PostOnly: int(rest[8]),
+		}
+		if ver >= binaryV2 {
+			v := func(i int) int64 { return int64(binary.BigEndian.Uint64(rest[9+8*i:])) }
+			e.LotSize, e.MinQty, e.MaxQty = v(0), v(1), v(2)
+			e.MinNotional, e.PriceBandBps, e.LastPrice = v(3), v(4), v(5)
+		}
+		s.Instruments = append(s.Instruments, e)
+	}
+
+	var seen uint64
+	var block []byte
+	s.Levels = make([]LevelEntry, 0, nLevels)
+	for range nLevels {
+		var l [4]byte
+		if _, err := io.ReadFull(br, l[:]); err != nil {
+			return nil, err
+		}
+		n := binary.BigEndian.Uint32(l[:])
+		if cap(block) < int(n) {
+			block = make([]byte, n)
+		}
+		block = block[:n]
+		if _, err := io.ReadFull(br, block); err != nil {
+			return nil, err
+		}
+
+		lvl, err := decodeLevel(block)
+		if err != nil {
+			return nil, err
+		}
+		seen += uint64(len(lvl.Orders))
+		s.Levels = append(s.Levels, lvl)
+	}
+
+	if seen != nOrders {
+		return nil, fmt.Errorf("%w: header says %d orders, found %d", ErrCorrupt, nOrders, seen)
+	}
+	return s, nil
+}
+
+func readSymbol(br *bufio.Reader) (string, error) {
+	n, err := br.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(br, b); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func decodeLevel(b []byte) (LevelEntry, error) {
+	var lvl LevelEntry
+
+	if len(b) < 1 || len(b) < 1+int(b[0])+1+8+4 {
+		return lvl, ErrCorrupt
+	}
+	n := int(b[0])
+	lvl.Symbol = string(b[1 : 1+n])
+	b = b[1+n:]
+
+	lvl.Side = int(b[0])
+	lvl.Price = int64(binary.BigEndian.Uint64(b[1:9]))
+	count := int(binary.BigEndian.Uint32(b[9:13]))
+	b = b[13:]
+
+	if len(b) != count*orderSize {
+		return lvl, ErrCorrupt
+	}
+
+	lvl.Orders = make([]OrderEntry, count)
+	for i := range lvl.Orders {
+		o := b[i*orderSize:]
+		lvl.Orders[i] = OrderEntry{
+			ID:     binary.BigEndian.Uint64(o[0:8]),
+			Side:   lvl.Side,
+			Type:   int(o[8]),
+			Price:  lvl.Price,
+			Qty:    int64(binary.BigEndian.Uint64(o[9:17])),
+			Filled: int64(binary.BigEndian.Uint64(o[17:25])),
+			SeqID:  binary.BigEndian.Uint64(o[25:33]),
+			UserID: binary.BigEndian.Uint64(o[33:41]),
+		}
+	}
+	return lvl, nil
+}
@@ -0,0 +1,137 @@
+package snapshot
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+Snapshot files
+
+	snapshot-<seq>.bin   one per retained snapshot, seq zero-padded
+	snapshot.tmp         in-flight write, never loaded
+	snapshot.bin         legacy single gob file, no footer
+
+The body is the binary format in codec.go, or gob for files
+written before it. Every snapshot-<seq>.bin ends with a footer:
+
+	[body len:8][crc32 of body:4][magic:4]
+
+A file is only renamed into place after it and its footer are
+fsynced, so a valid footer means a complete snapshot.
+*/
+
+const (
+	// fileName is the single file written before seq-named
+	// snapshots. It is still loaded if nothing newer validates.
+	fileName = "snapshot.bin"
+	tmpName  = "snapshot.tmp"
+
+	footerMagic = "LKS1"
+	footerSize  = 8 + 4 + 4
+)
+
+var ErrBadChecksum = errors.New("snapshot: checksum mismatch")
+var ErrNoFooter = errors.New("snapshot: missing or truncated footer")
+
+func snapshotName(seq uint64) string {
+	return fmt.Sprintf("snapshot-%020d.bin", seq)
+}
+
+// listSnapshots returns the seq-named snapshot files in dir,
+// newest first.
+func listSnapshots(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "snapshot-*.bin"))
+	if err != nil {
+		return nil, err
+	}
+
+	seqOf := func(path string) uint64 {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "snapshot-"), ".bin")
+		n, _ := strconv.ParseUint(name, 10, 64)
+		return n
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return seqOf(files[i]) > seqOf(files[j])
+	})
+	return files, nil
+}
+
+// footerWriter passes writes through while tracking the length
+// and checksum the footer needs.
+type footerWriter struct {
+	w   io.Writer
+	crc hash.Hash32
+	n   uint64
+}
+
+func newFooterWriter(w io.Writer) *footerWriter {
+	return &footerWriter{w: w, crc: crc32.NewIEEE()}
+}
+
+func (fw *footerWriter) Write(p []byte) (int, error) {
+	n, err := fw.w.Write(p)
+	fw.crc.Write(p[:n])
+	fw.n += uint64(n)
+	return n, err
+}
+
+// finish writes the footer for everything written so far.
+func (fw *footerWriter) finish() error {
+	var f [footerSize]byte
+	binary.BigEndian.PutUint64(f[0:8], fw.n)
+	binary.BigEndian.PutUint32(f[8:12], fw.crc.Sum32())
+	copy(f[12:], footerMagic)
+	_, err := fw.w.Write(f[:])
+	return err
+}
+
+// checkFooter validates f against its footer without loading it
+// and returns the body length.
+func checkFooter(f *os.File) (int64, error) {
+	st, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := st.Size()
+	if size < footerSize {
+		return 0, ErrNoFooter
+	}
+
+	var ft [footerSize]byte
+	if _, err := f.ReadAt(ft[:], size-footerSize); err != nil {
+		return 0, err
+	}
+	body := size - footerSize
+	if string(ft[12:]) != footerMagic || binary.BigEndian.Uint64(ft[0:8]) != uint64(body) {
+		return 0, ErrNoFooter
+	}
+
+	crc := crc32.NewIEEE()
+	if _, err := io.Copy(crc, io.NewSectionReader(f, 0, body)); err != nil {
+		return 0, err
+	}
+	if crc.Sum32() != binary.BigEndian.Uint32(ft[8:12]) {
+		return 0, ErrBadChecksum
+	}
+	return body, nil
+}
+
+// syncDir makes a rename or unlink in dir durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
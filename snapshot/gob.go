@@ -0,0 +1,18 @@
+package snapshot
+
+import (
+	"encoding/gob"
+	"io"
+)
+
+// readGob is the migration reader for snapshots written with
+// encoding/gob, both the legacy snapshot.bin and footered files
+// from before the binary format. Snapshot kept its field names,
+// so they decode into it unchanged.
+func readGob(r io.Reader) (*Snapshot, error) {
+	var s Snapshot
+	if err := gob.NewDecoder(r).Decode(&s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -1,30 +1,177 @@
 package snapshot
 
 import (
-	"encoding/gob"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
 	"os"
+	"path/filepath"
 
 	"loki/domain/orderbook"
 	"loki/infra/memory"
 )
 
-func Load(
-	path string,
-	book *orderbook.OrderBook,
+// LoadLatest restores the newest snapshot in dir that validates
+// and returns its seq, or 0 if there is none. Snapshots that fail
+// validation are skipped with a warning in favour of older ones.
+func LoadLatest(
+	dir string,
+	books *orderbook.Registry,
 	pool *memory.Pool[orderbook.Order],
 ) (uint64, error) {
+	s, _, err := ReadLatest(dir)
+	if err != nil || s == nil {
+		return 0, err
+	}
+	if err := Restore(s, books, pool); err != nil {
+		return 0, err
+	}
+	return s.Seq, nil
+}
+
+// ReadLatest returns the newest snapshot in dir that validates,
+// and its path. It returns nil if there is none.
+func ReadLatest(dir string) (*Snapshot, string, error) {
+	files, err := listSnapshots(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	// the legacy single file is the last resort
+	files = append(files, filepath.Join(dir, fileName))
+
+	for _, path := range files {
+		s, err := Read(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			log.Printf("snapshot: skipping %s: %v", path, err)
+			continue
+		}
+		return s, path, nil
+	}
+	return nil, "", nil
+}
+
+// Read validates and decodes one snapshot file without touching
+// any book. Gob snapshots from before the binary format are
+// still read.
+func Read(path string) (*Snapshot, error) {
 	f, err := os.Open(path)
 	if err != nil {
-		return 0, nil // snapshot optional
+		return nil, err
 	}
 	defer f.Close()
 
-	var s Snapshot
-	if err := gob.NewDecoder(f).Decode(&s); err != nil {
+	var body int64
+	if filepath.Base(path) == fileName {
+		st, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		body = st.Size()
+	} else if body, err = checkFooter(f); err != nil {
+		return nil, err
+	}
+
+	r := bufio.NewReader(io.NewSectionReader(f, 0, body))
+	if prefix, _ := r.Peek(4); isBinary(prefix) {
+		return decodeBinary(r)
+	}
+	return readGob(r)
+}
+
+func Load(
+	path string,
+	books *orderbook.Registry,
+	pool *memory.Pool[orderbook.Order],
+) (uint64, error) {
+	s, err := Read(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil // snapshot optional
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := Restore(s, books, pool); err != nil {
 		return 0, err
 	}
+	return s.Seq, nil
+}
 
+// Restore rebuilds books from s. Levels are restored as they
+// were, without matching, so each queue keeps its FIFO order and
+// fill state.
+func Restore(
+	s *Snapshot,
+	books *orderbook.Registry,
+	pool *memory.Pool[orderbook.Order],
+) error {
+	for _, e := range s.Instruments {
+		book := books.Get(e.Symbol)
+		if book != nil {
+			book.LastPrice = e.LastPrice
+			continue
+		}
+		book, err := books.Create(orderbook.Instrument{
+			Symbol:   e.Symbol,
+			TickSize: e.TickSize,
+			PostOnly: orderbook.PostOnlyPolicy(e.PostOnly),
+			Rules: orderbook.Rules{
+				LotSize:      e.LotSize,
+				MinQty:       e.MinQty,
+				MaxQty:       e.MaxQty,
+				MinNotional:  e.MinNotional,
+				PriceBandBps: e.PriceBandBps,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		book.LastPrice = e.LastPrice
+	}
+
+	for _, lvl := range s.Levels {
+		book := books.Get(lvl.Symbol)
+		if book == nil {
+			return fmt.Errorf("snapshot level %d: %w %q", lvl.Price, orderbook.ErrUnknownSymbol, lvl.Symbol)
+		}
+
+		for _, e := range lvl.Orders {
+			o := pool.Get()
+			*o = orderbook.Order{
+				ID:     e.ID,
+				Side:   orderbook.Side(lvl.Side),
+				Type:   orderbook.OrderType(e.Type),
+				Price:  lvl.Price,
+				Qty:    e.Qty,
+				Filled: e.Filled,
+				SeqID:  e.SeqID,
+				UserID: e.UserID,
+			}
+			if err := book.Restore(o); err != nil {
+				return fmt.Errorf("snapshot order %d: %w", e.ID, err)
+			}
+		}
+	}
+
+	// Older snapshots: a flat list without fill state, placed in
+	// walk order.
 	for _, e := range s.Orders {
+		// Snapshots written before multi-instrument support
+		// carry no symbol.
+		symbol := e.Symbol
+		if symbol == "" {
+			symbol = orderbook.DefaultSymbol
+		}
+		book := books.Get(symbol)
+		if book == nil {
+			return fmt.Errorf("snapshot order %d: %w %q", e.ID, orderbook.ErrUnknownSymbol, symbol)
+		}
+
 		o := pool.Get()
 		*o = orderbook.Order{
 			ID:     e.ID,
@@ -38,5 +185,5 @@ func Load(
 		book.Place(o)
 	}
 
-	return s.Seq, nil
+	return nil
 }
@@ -3,15 +3,49 @@ package snapshot
 import "time"
 
 type Snapshot struct {
-	Seq     uint64
-	Created time.Time
-	Orders  []OrderEntry
+	Seq         uint64
+	Created     time.Time
+	Instruments []InstrumentEntry
+	Levels      []LevelEntry
+
+	// Orders is the flat list older snapshots were written with.
+	// It is only read, never written.
+	Orders []OrderEntry
+}
+
+type InstrumentEntry struct {
+	Symbol   string
+	TickSize int64
+	PostOnly int
+
+	LotSize      int64
+	MinQty       int64
+	MaxQty       int64
+	MinNotional  int64
+	PriceBandBps int64
+
+	// LastPrice anchors the price band, so it must survive a
+	// restore for replay to band-check the same way.
+	LastPrice int64
+}
+
+// LevelEntry is one price level, its orders in queue order.
+// Bids come best (highest) first, then asks best (lowest) first.
+type LevelEntry struct {
+	Symbol string
+	Side   int
+	Price  int64
+	Orders []OrderEntry
 }
 
 type OrderEntry struct {
-	ID    uint64
-	Side  int
-	Type  int
-	Price int64
-	Qty   int64
+	Symbol string
+	ID     uint64
+	Side   int
+	Type   int
+	Price  int64
+	Qty    int64
+	Filled int64
+	SeqID  uint64
+	UserID uint64
 }
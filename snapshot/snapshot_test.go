@@ -0,0 +1,202 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"loki/domain/orderbook"
+	"loki/infra/memory"
+)
+
+func newPool() *memory.Pool[orderbook.Order] {
+	return memory.NewPool(func() *orderbook.Order {
+		return &orderbook.Order{}
+	})
+}
+
+// buildBooks leaves partially filled orders queued behind and
+// ahead of untouched ones on both sides.
+func buildBooks(t *testing.T) *orderbook.Registry {
+	t.Helper()
+
+	books := orderbook.NewRegistry()
+	book, err := books.Create(orderbook.Instrument{
+		Symbol:   "BTC-USD",
+		TickSize: 1,
+		Rules:    orderbook.Rules{LotSize: 1, MaxQty: 1000, MinNotional: 50, PriceBandBps: 500},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seq := uint64(0)
+	place := func(side orderbook.Side, price, qty int64) {
+		seq++
+		o := &orderbook.Order{
+			ID: seq, SeqID: seq, UserID: 100 + seq,
+			Side: side, Type: orderbook.Limit, Price: price, Qty: qty,
+		}
+		if _, err := book.Place(o); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	place(orderbook.Bid, 99, 5)
+	place(orderbook.Bid, 99, 3)
+	place(orderbook.Bid, 98, 4)
+	place(orderbook.Ask, 101, 6)
+	place(orderbook.Ask, 101, 2)
+	place(orderbook.Ask, 99, 2)  // fills 2 of the first bid at 99
+	place(orderbook.Bid, 101, 1) // fills 1 of the first ask at 101
+	return books
+}
+
+func TestRestoreRoundTrip(t *testing.T) {
+	orig := Capture(7, buildBooks(t))
+
+	books := orderbook.NewRegistry()
+	if err := Restore(orig, books, newPool()); err != nil {
+		t.Fatal(err)
+	}
+	again := Capture(7, books)
+	again.Created = orig.Created
+
+	if !reflect.DeepEqual(orig, again) {
+		t.Fatalf("restored snapshot differs:\n got %+v\nwant %+v", again, orig)
+	}
+
+	head := orig.Levels[0].Orders[0]
+	if head.ID != 1 || head.Filled != 2 || head.UserID != 101 {
+		t.Fatalf("best bid head: %+v", head)
+	}
+	if inst := orig.Instruments[0]; inst.LastPrice != 101 || inst.PriceBandBps != 500 {
+		t.Fatalf("instrument rules not captured: %+v", inst)
+	}
+}
+
+func TestRestoreKeepsPriority(t *testing.T) {
+	books := orderbook.NewRegistry()
+	if err := Restore(Capture(7, buildBooks(t)), books, newPool()); err != nil {
+		t.Fatal(err)
+	}
+	book := books.Get("BTC-USD")
+
+	// sweeps 99: order 1's remaining 3, then 2 of order 2
+	trades, err := book.Place(&orderbook.Order{
+		ID: 8, SeqID: 8, Side: orderbook.Ask, Type: orderbook.Limit, Price: 99, Qty: 5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 2 || trades[0].MakerID != 1 || trades[0].Qty != 3 || trades[1].MakerID != 2 {
+		t.Fatalf("trades: %+v", trades)
+	}
+}
+
+func TestWriterRetainsAndFallsBack(t *testing.T) {
+	dir := t.TempDir()
+	books := buildBooks(t)
+
+	w := &Writer{Dir: dir, Retain: 2}
+	for seq := uint64(5); seq <= 7; seq++ {
+		if err := w.WriteSnapshot(Capture(seq, books)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, _ := listSnapshots(dir)
+	if len(files) != 2 || filepath.Base(files[0]) != snapshotName(7) {
+		t.Fatalf("retained %v", files)
+	}
+
+	// a crash mid-write leaves only the temp file behind
+	_ = os.WriteFile(filepath.Join(dir, tmpName), []byte("partial"), 0o644)
+
+	// flip a byte in the newest snapshot
+	b, _ := os.ReadFile(files[0])
+	b[len(b)/2] ^= 0xff
+	_ = os.WriteFile(files[0], b, 0o644)
+
+	if _, err := Read(files[0]); !errors.Is(err, ErrBadChecksum) {
+		t.Fatalf("corrupt snapshot: err=%v", err)
+	}
+
+	fresh := orderbook.NewRegistry()
+	seq, err := LoadLatest(dir, fresh, newPool())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seq != 6 || fresh.Get("BTC-USD") == nil {
+		t.Fatalf("fell back to seq %d", seq)
+	}
+}
+
+func TestLoadLatestEmpty(t *testing.T) {
+	seq, err := LoadLatest(t.TempDir(), orderbook.NewRegistry(), newPool())
+	if seq != 0 || err != nil {
+		t.Fatalf("empty dir: seq %d, err %v", seq, err)
+	}
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	orig := Capture(7, buildBooks(t))
+
+	for _, compress := range []bool{false, true} {
+		dir := t.TempDir()
+		w := &Writer{Dir: dir, Compress: compress}
+		if err := w.WriteSnapshot(orig); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := Read(filepath.Join(dir, snapshotName(7)))
+		if err != nil {
+			t.Fatalf("compress=%v: %v", compress, err)
+		}
+		if !got.Created.Equal(orig.Created) {
+			t.Fatalf("compress=%v: created %v, want %v", compress, got.Created, orig.Created)
+		}
+		got.Created = orig.Created
+		if !reflect.DeepEqual(got, orig) {
+			t.Fatalf("compress=%v:\n got %+v\nwant %+v", compress, got, orig)
+		}
+	}
+}
+
+func TestReadGobSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	orig := Capture(3, buildBooks(t))
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(orig); err != nil {
+		t.Fatal(err)
+	}
+
+	// legacy single file, no footer
+	legacy := filepath.Join(dir, fileName)
+	_ = os.WriteFile(legacy, buf.Bytes(), 0o644)
+	if got, err := Read(legacy); err != nil || got.Seq != 3 || len(got.Levels) != len(orig.Levels) {
+		t.Fatalf("legacy gob: %+v, %v", got, err)
+	}
+
+	// seq-named gob file with a footer
+	var footered bytes.Buffer
+	fw := newFooterWriter(&footered)
+	_, _ = fw.Write(buf.Bytes())
+	_ = fw.finish()
+	path := filepath.Join(dir, snapshotName(3))
+	_ = os.WriteFile(path, footered.Bytes(), 0o644)
+
+	fresh := orderbook.NewRegistry()
+	seq, err := LoadLatest(dir, fresh, newPool())
+	if err != nil || seq != 3 {
+		t.Fatalf("footered gob: seq %d, %v", seq, err)
+	}
+	if fresh.Get("BTC-USD").Get(1).Filled != 2 {
+		t.Fatal("footered gob: fill state lost")
+	}
+}
@@ -1,7 +1,7 @@
 package snapshot
 
 import (
-	"encoding/gob"
+	"bufio"
 	"os"
 	"path/filepath"
 	"time"
@@ -9,49 +9,156 @@ import (
 	"loki/domain/orderbook"
 )
 
+// defaultRetain is how many snapshots Writer keeps when Retain
+// is not set.
+const defaultRetain = 3
+
 type Writer struct {
 	Dir string
+
+	// Retain is how many of the newest snapshots to keep.
+	Retain int
+
+	// Compress zstd-compresses everything after the header.
+	Compress bool
+}
+
+// Capture copies every book into a Snapshot taken at seq. The
+// caller must make sure nothing mutates books meanwhile and that
+// seq is exactly the last command applied to them.
+func Capture(seq uint64, books *orderbook.Registry) *Snapshot {
+	s := &Snapshot{
+		Seq:     seq,
+		Created: time.Now(),
+	}
+
+	books.Walk(func(book *orderbook.OrderBook) {
+		s.Instruments = append(s.Instruments, InstrumentEntry{
+			Symbol:   book.Symbol,
+			TickSize: book.TickSize,
+			PostOnly: int(book.PostOnly),
+
+			LotSize:      book.Rules.LotSize,
+			MinQty:       book.Rules.MinQty,
+			MaxQty:       book.Rules.MaxQty,
+			MinNotional:  book.Rules.MinNotional,
+			PriceBandBps: book.Rules.PriceBandBps,
+			LastPrice:    book.LastPrice,
+		})
+
+		collect := func(side orderbook.Side) func(*orderbook.PriceLevel) {
+			return func(lvl *orderbook.PriceLevel) {
+				e := LevelEntry{
+					Symbol: book.Symbol,
+					Side:   int(side),
+					Price:  lvl.Price,
+					Orders: make([]OrderEntry, 0, lvl.OrderCount),
+				}
+				for o := lvl.Head(); o != nil; o = o.Next() {
+					if o.Status != orderbook.Active {
+						continue
+					}
+					e.Orders = append(e.Orders, OrderEntry{
+						ID:     o.ID,
+						Side:   int(o.Side),
+						Type:   int(o.Type),
+						Price:  o.Price,
+						Qty:    o.Qty,
+						Filled: o.Filled,
+						SeqID:  o.SeqID,
+						UserID: o.UserID,
+					})
+				}
+				if len(e.Orders) > 0 {
+					s.Levels = append(s.Levels, e)
+				}
+			}
+		}
+
+		book.BidsWalk(collect(orderbook.Bid))
+		book.AsksWalk(collect(orderbook.Ask))
+	})
+
+	return s
 }
 
-func (w *Writer) Write(seq uint64, book *orderbook.OrderBook) error {
+// Write captures books and persists them in one go. It has the
+// same requirements as Capture.
+func (w *Writer) Write(seq uint64, books *orderbook.Registry) error {
+	return w.WriteSnapshot(Capture(seq, books))
+}
+
+// WriteSnapshot persists a captured snapshot as
+// snapshot-<seq>.bin: written to a temp file, fsynced, renamed
+// into place and the directory fsynced. Older snapshots beyond
+// Retain are then removed.
+func (w *Writer) WriteSnapshot(s *Snapshot) error {
 	if err := os.MkdirAll(w.Dir, 0755); err != nil {
 		return err
 	}
 
-	path := filepath.Join(w.Dir, "snapshot.bin")
-	f, err := os.Create(path)
+	tmp := filepath.Join(w.Dir, tmpName)
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
 
-	s := Snapshot{
-		Seq:     seq,
-		Created: time.Now(),
-		Orders:  make([]OrderEntry, 0, 1024),
+	bw := bufio.NewWriterSize(f, 256<<10)
+	fw := newFooterWriter(bw)
+	if err := encodeBinary(fw, s, w.Compress); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := fw.finish(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
 	}
 
-	book.BidsWalk(func(lvl *orderbook.PriceLevel) {
-		for o := lvl.Head(); o != nil; o = o.Next() {
-			if o.Status == orderbook.Active {
-				s.Orders = append(s.Orders, OrderEntry{
-					ID: o.ID, Side: int(o.Side),
-					Type: int(o.Type), Price: o.Price, Qty: o.Qty,
-				})
-			}
-		}
-	})
+	if err := os.Rename(tmp, w.Path(s.Seq)); err != nil {
+		return err
+	}
+	if err := syncDir(w.Dir); err != nil {
+		return err
+	}
 
-	book.AsksWalk(func(lvl *orderbook.PriceLevel) {
-		for o := lvl.Head(); o != nil; o = o.Next() {
-			if o.Status == orderbook.Active {
-				s.Orders = append(s.Orders, OrderEntry{
-					ID: o.ID, Side: int(o.Side),
-					Type: int(o.Type), Price: o.Price, Qty: o.Qty,
-				})
-			}
-		}
-	})
+	return w.prune()
+}
+
+// Path is where the snapshot at seq is written.
+func (w *Writer) Path(seq uint64) string {
+	return filepath.Join(w.Dir, snapshotName(seq))
+}
+
+// prune removes all but the newest Retain snapshots.
+func (w *Writer) prune() error {
+	keep := w.Retain
+	if keep <= 0 {
+		keep = defaultRetain
+	}
+
+	files, err := listSnapshots(w.Dir)
+	if err != nil {
+		return err
+	}
+	if len(files) <= keep {
+		return nil
+	}
 
-	return gob.NewEncoder(f).Encode(&s)
+	for _, path := range files[keep:] {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return syncDir(w.Dir)
 }